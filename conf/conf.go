@@ -54,9 +54,40 @@ type ServerConf struct {
 	Deadline        time.Duration ///< Deadline for connection (in milliseconds) (default 1000)
 	Game            GameConf      ///< Game configurations
 	TimeoutForLogin time.Duration ///< Timeout for login in seconds (default 120)
-	DictPath        string        ///< Russian language Dictionary path
+	DictPath        string        ///< Russian language Dictionary path (used if Dictionaries is empty)
+	Dictionaries    []DictConf    ///< Dictionaries of all supported languages
+	Rulesets        []RulesetConf ///< Rulesets which filter words of every dictionary
 	SystemLogin     string
 	WaitTime        time.Duration
+	Admins          []string ///< Logins of users which can run admin commands
+}
+
+/**
+ * @class DictConf
+ * @brief Class, provides configuration for dictionary of one language
+ */
+type DictConf struct {
+	Name        string            ///< Name of language, which users choose
+	Path        string            ///< Dictionary path
+	Compiled    string            ///< Path to dictionary compiled by compile-dict command (used if it is fresh)
+	Definitions string            ///< Path to short definitions of words (optional)
+	StartWords  string            ///< Path to curated list of start words, one per line (optional)
+	Blocklist   string            ///< Path to offensive words, which are never shown by server (optional)
+	Alphabet    string            ///< Letters of language (taken from dictionary if empty)
+	Fold        map[string]string ///< Letters replaced in dictionary and player input (e.g. ё -> е)
+}
+
+/**
+ * @class RulesetConf
+ * @brief Class, provides configuration for policy which words are allowed in game
+ *
+ * Tags are taken from tagged dictionary (e.g. NOUN, sing, nomn).
+ * Ruleset with required tags isn't available for dictionary without tags
+ */
+type RulesetConf struct {
+	Name    string   ///< Name of ruleset, which users choose
+	Require []string ///< Allowed words must have all these tags
+	Exclude []string ///< Allowed words must not have any of these tags
 }
 
 /**
@@ -83,6 +114,28 @@ type GameConf struct {
 	AreaSize           int           ///< Length side of the playing area (default 5)
 	NumberUsersPerGame int           ///< Maximum number of gaming users at a time (default 4)
 	MaxUsernameLength  int           ///< Maximum username length (default 255)
+	Language           string        ///< Default language of new games (default ru)
+	Ruleset            string        ///< Default ruleset of new games (all words of dictionary if empty)
+	StartWord          StartWordConf ///< Policy of start word selection
+	TurnOrder          string        ///< Turn order enum(join, random, rating) (default join)
+	Mode               string        ///< Default game mode enum(grid, classic, hunt) (default grid)
+	HuntTime           time.Duration ///< Duration of hunt mode game in seconds (default 120)
+	Teams              bool          ///< Flag if new games are played by two teams, needs even NumberUsersPerGame (default false)
+	ChallengeTime      time.Duration ///< Time in seconds to challenge word and to vote (default 30)
+	CorrMoveTime       time.Duration ///< Time in hours for a move of correspondence game (default 24)
+	NoShowTime         time.Duration ///< Time in minutes to join tournament game, otherwise player loses (default 10)
+	Puzzles            string        ///< Path to puzzles made by generate-puzzles command (optional)
+	DailySecret        int64         ///< Secret mixed into seed of daily puzzle, so it can't be generated in advance (optional)
+}
+
+/**
+ * @class StartWordConf
+ * @brief Class, provides configuration for start word selection
+ */
+type StartWordConf struct {
+	Policy   string ///< Policy enum(random, admin, list, balanced) (default random)
+	MinMoves int    ///< Minimum number of legal moves after balanced start word (default 10)
+	Attempts int    ///< Maximum number of words tried to find balanced start word (default 20)
 }
 
 /**
@@ -114,5 +167,64 @@ func New(file string) (*Config, error) {
 		return nil, errors.New("Wrong value: 'Stage'")
 	}
 
+	if len(config.Server.Dictionaries) == 0 && config.Server.DictPath != "" {
+		config.Server.Dictionaries = []DictConf{{Name: "ru", Path: config.Server.DictPath}}
+	}
+
+	if config.Server.Game.Language == "" && len(config.Server.Dictionaries) > 0 {
+		config.Server.Game.Language = config.Server.Dictionaries[0].Name
+	}
+
+	start := &config.Server.Game.StartWord
+	switch start.Policy {
+	case "":
+		start.Policy = "random"
+	case "random", "admin", "list", "balanced":
+	default:
+		return nil, errors.New("Wrong value: 'StartWord.Policy'")
+	}
+	if start.MinMoves <= 0 {
+		start.MinMoves = 10
+	}
+	if start.Attempts <= 0 {
+		start.Attempts = 20
+	}
+
+	switch config.Server.Game.Mode {
+	case "":
+		config.Server.Game.Mode = "grid"
+	case "grid", "classic", "hunt":
+	default:
+		return nil, errors.New("Wrong value: 'Mode'")
+	}
+
+	if config.Server.Game.Teams && config.Server.Game.NumberUsersPerGame%2 != 0 {
+		return nil, errors.New("Wrong value: 'Teams', team play needs even 'NumberUsersPerGame'")
+	}
+
+	if config.Server.Game.HuntTime <= 0 {
+		config.Server.Game.HuntTime = 120
+	}
+
+	if config.Server.Game.ChallengeTime <= 0 {
+		config.Server.Game.ChallengeTime = 30
+	}
+
+	if config.Server.Game.CorrMoveTime <= 0 {
+		config.Server.Game.CorrMoveTime = 24
+	}
+
+	if config.Server.Game.NoShowTime <= 0 {
+		config.Server.Game.NoShowTime = 10
+	}
+
+	switch config.Server.Game.TurnOrder {
+	case "":
+		config.Server.Game.TurnOrder = "join"
+	case "join", "random", "rating":
+	default:
+		return nil, errors.New("Wrong value: 'TurnOrder'")
+	}
+
 	return config, nil
 }
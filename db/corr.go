@@ -0,0 +1,224 @@
+/**
+ *
+ * @file corr.go
+ * @brief Database
+ *
+ * Correspondence games, which are stored entirely in database,
+ * so players can make moves in different connections
+ */
+
+package db
+
+import (
+	// System
+	"strings"
+	"time"
+
+	// Third-party
+	"github.com/jinzhu/gorm"
+	// Project
+)
+
+/**
+ *
+ * @class CorrGame
+ * @brief The table contains state of correspondence games.
+ *
+ * Setup of the game (language, ruleset, players in order of their moves)
+ * is stored in GameSession. Area contains letters row by row, '-' for empty cells.
+ */
+type CorrGame struct {
+	gorm.Model
+
+	GameID      uint   `gorm:"index"`
+	Area        string `gorm:"type:VARCHAR(255) CHARACTER SET utf8 COLLATE utf8_general_ci"`
+	UsedWords   string `gorm:"type:TEXT CHARACTER SET utf8 COLLATE utf8_general_ci"`
+	Turn        int    `gorm:"default:0"`
+	Skipped     int    `gorm:"default:0"`
+	Moves       uint   `gorm:"default:0"`
+	MoveTime    int64
+	Deadline    time.Time
+	Finished    bool        `gorm:"default:false"`
+	GameSession GameSession `gorm:"ForeignKey:GameID"`
+}
+
+/**
+ *
+ * @class CorrPlayer
+ * @brief The table contains players of correspondence games and their current scores.
+ *
+ */
+type CorrPlayer struct {
+	gorm.Model
+
+	CorrGameID uint `gorm:"index"`
+	UserID     uint
+	Position   int
+	Score      int  `gorm:"default:0"`
+	User       User `gorm:"ForeignKey:UserID"`
+}
+
+/**
+ *
+ * @brief Creates correspondence game with its game session.
+ * @param[in] mode of the game
+ * @param[in] seed of random generator of the game
+ * @param[in] language of dictionary
+ * @param[in] ruleset of the game
+ * @param[in] start policy which chose start word
+ * @param[in] start word
+ * @param[in] area letters row by row
+ * @param[in] used words
+ * @param[in] players in order of their moves
+ * @param[in] time for a move
+ * @return the record just created for the new game.
+ * @return error
+ *
+ * Game session with its setup, players in it and the correspondence game
+ * are created in one transaction, so a failed game doesn't leave half-saved rows.
+ */
+func CreateCorrGame(mode string, seed int64, language string, ruleset string, startPolicy string, startWord string,
+	area string, usedWords []string, players []string, moveTime time.Duration) (*CorrGame, error) {
+
+	users := []User{}
+	if res := db.Where("name in (?)", players).Find(&users); res.Error != nil {
+		return nil, res.Error
+	}
+	ids := make(map[string]uint)
+	for i := range users {
+		ids[users[i].Name] = users[i].ID
+	}
+	for _, name := range players {
+		if _, ok := ids[name]; !ok {
+			return nil, gorm.ErrRecordNotFound
+		}
+	}
+
+	tx := db.Begin()
+	gameSession := GameSession{
+		Mode:        mode,
+		Seed:        seed,
+		Language:    language,
+		Ruleset:     ruleset,
+		StartPolicy: startPolicy,
+		StartWord:   startWord,
+		Players:     strings.Join(players, ","),
+		Ranked:      true,
+	}
+	if res := tx.Create(&gameSession); res.Error != nil {
+		tx.Rollback()
+		return nil, res.Error
+	}
+	for _, name := range players {
+		userInGame := UserInGame{UserID: ids[name], GameID: gameSession.ID}
+		if res := tx.Create(&userInGame); res.Error != nil {
+			tx.Rollback()
+			return nil, res.Error
+		}
+	}
+
+	corrGame := CorrGame{
+		GameID:    gameSession.ID,
+		Area:      area,
+		UsedWords: strings.Join(usedWords, ","),
+		MoveTime:  int64(moveTime / time.Second),
+		Deadline:  time.Now().Add(moveTime),
+	}
+	if res := tx.Create(&corrGame); res.Error != nil {
+		tx.Rollback()
+		return nil, res.Error
+	}
+	for i, name := range players {
+		player := CorrPlayer{CorrGameID: corrGame.ID, UserID: ids[name], Position: i}
+		if res := tx.Create(&player); res.Error != nil {
+			tx.Rollback()
+			return nil, res.Error
+		}
+	}
+	if res := tx.Commit(); res.Error != nil {
+		return nil, res.Error
+	}
+	return &corrGame, nil
+}
+
+/**
+ *
+ * @brief Returns correspondence game.
+ * @param[in] id of correspondence game
+ * @return game record with its game session
+ * @return players in order of their moves
+ * @return error
+ *
+ */
+func CorrGameByID(id uint) (*CorrGame, []CorrPlayer, error) {
+
+	corrGame := CorrGame{}
+	if res := db.Where("id = ?", id).Preload("GameSession").First(&corrGame); res.Error != nil {
+		return nil, nil, res.Error
+	}
+
+	players := []CorrPlayer{}
+	if res := db.
+		Where("corr_game_id = ?", id).
+		Order("position").
+		Preload("User").
+		Find(&players); res.Error != nil {
+		return nil, nil, res.Error
+	}
+	return &corrGame, players, nil
+}
+
+/**
+ *
+ * @brief Saves state of correspondence game.
+ * @param[in] game record
+ * @param[in] players with their scores
+ * @return error
+ *
+ */
+func SaveCorrGame(corrGame *CorrGame, players []CorrPlayer) error {
+
+	tx := db.Set("gorm:save_associations", false).Begin()
+	if res := tx.Save(corrGame); res.Error != nil {
+		tx.Rollback()
+		return res.Error
+	}
+	for i := range players {
+		if res := tx.Save(&players[i]); res.Error != nil {
+			tx.Rollback()
+			return res.Error
+		}
+	}
+	return tx.Commit().Error
+}
+
+/**
+ *
+ * @brief Returns ids of running correspondence games of user.
+ * @param[in] username of user
+ * @return ids of games from the oldest one
+ * @return error
+ *
+ */
+func UserCorrGames(username string) ([]uint, error) {
+
+	user := User{}
+	if res := db.Where("name = ?", username).First(&user); res.Error != nil {
+		return nil, res.Error
+	}
+
+	players := []CorrPlayer{}
+	if res := db.
+		Joins("JOIN corr_games ON corr_games.id = corr_players.corr_game_id").
+		Where("corr_players.user_id = ? and corr_games.finished = ?", user.ID, false).
+		Order("corr_players.corr_game_id").
+		Find(&players); res.Error != nil {
+		return nil, res.Error
+	}
+
+	ids := make([]uint, len(players))
+	for i := range players {
+		ids[i] = players[i].CorrGameID
+	}
+	return ids, nil
+}
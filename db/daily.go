@@ -0,0 +1,148 @@
+/**
+ *
+ * @file daily.go
+ * @brief Database
+ *
+ * Results of daily puzzles
+ */
+
+package db
+
+import (
+	// System
+
+	// Third-party
+	"github.com/jinzhu/gorm"
+	// Project
+)
+
+/**
+ *
+ * @class DailyResult
+ * @brief The table contains the best move of every user in daily puzzle.
+ *
+ * Day is a date of puzzle in format YYYY-MM-DD, user has the only result of the day.
+ */
+type DailyResult struct {
+	gorm.Model
+
+	Day      string `gorm:"type:VARCHAR(10);unique_index:idx_daily_results_day_user"`
+	UserID   uint   `gorm:"unique_index:idx_daily_results_day_user"`
+	Word     string `gorm:"type:VARCHAR(100) CHARACTER SET utf8 COLLATE utf8_general_ci"`
+	Score    uint   `gorm:"default:0"`
+	Attempts uint   `gorm:"default:0"`
+	User     User   `gorm:"ForeignKey:UserID"`
+}
+
+/**
+ *
+ * @brief Returns result of user in daily puzzle.
+ * @param[in] day of puzzle
+ * @param[in] username of user
+ * @return result of user (empty result with zero attempts if user hasn't tried yet)
+ * @return error
+ *
+ */
+func DailyResultOf(day string, username string) (*DailyResult, error) {
+
+	user := User{}
+	if res := db.Where("name = ?", username).First(&user); res.Error != nil {
+		return nil, res.Error
+	}
+
+	result := DailyResult{}
+	res := db.Where("day = ? and user_id = ?", day, user.ID).First(&result)
+	if res.RecordNotFound() {
+		return &DailyResult{Day: day, UserID: user.ID, User: user}, nil
+	}
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	result.User = user
+	return &result, nil
+}
+
+/**
+ *
+ * @brief Saves attempt of user in daily puzzle.
+ * @param[in] day of puzzle
+ * @param[in] username of user
+ * @param[in] word made by user (empty if move is wrong)
+ * @param[in] score of move
+ * @param[in] limit of attempts
+ * @return result of user with the best move
+ * @return saved is false if user has used all attempts (result isn't changed then)
+ * @return error
+ *
+ * Attempts ++, word and score are replaced only by better move.
+ * Row of result is locked, so attempts made at the same time are all counted and limited
+ */
+func SaveDailyResult(day string, username string, word string, score uint, limit uint) (*DailyResult, bool, error) {
+
+	user := User{}
+	if res := db.Where("name = ?", username).First(&user); res.Error != nil {
+		return nil, false, res.Error
+	}
+
+	tx := db.Begin()
+	if res := tx.
+		Set("gorm:insert_option", "ON DUPLICATE KEY UPDATE id = id").
+		Create(&DailyResult{Day: day, UserID: user.ID}); res.Error != nil {
+		tx.Rollback()
+		return nil, false, res.Error
+	}
+
+	result := DailyResult{}
+	if res := tx.
+		Set("gorm:query_option", "FOR UPDATE").
+		Where("day = ? and user_id = ?", day, user.ID).
+		First(&result); res.Error != nil {
+		tx.Rollback()
+		return nil, false, res.Error
+	}
+	result.User = user
+
+	if result.Attempts >= limit {
+		if err := tx.Rollback().Error; err != nil {
+			return nil, false, err
+		}
+		return &result, false, nil
+	}
+
+	result.Attempts++
+	if score > result.Score {
+		result.Score = score
+		result.Word = word
+	}
+	if res := tx.Set("gorm:save_associations", false).Save(&result); res.Error != nil {
+		tx.Rollback()
+		return nil, false, res.Error
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, false, err
+	}
+	return &result, true, nil
+}
+
+/**
+ *
+ * @brief Returns leaderboard of daily puzzle.
+ * @param[in] day of puzzle
+ * @param[in] limit of results
+ * @return results sorted by score, earlier results are higher among equal ones
+ * @return error
+ *
+ */
+func DailyLeaderboard(day string, limit uint) ([]DailyResult, error) {
+
+	results := []DailyResult{}
+	if res := db.
+		Where("day = ? and score > 0", day).
+		Order("score desc, updated_at").
+		Limit(limit).
+		Preload("User").
+		Find(&results); res.Error != nil {
+		return nil, res.Error
+	}
+	return results, nil
+}
@@ -20,10 +20,13 @@ package db
 import (
 	// System
 	//"bufio"
+	"errors"
 	"fmt"
 	"hash/fnv"
 	"os"
+	"sort"
 	"strings"
+	"time"
 
 	// Third-party
 	_ "github.com/go-sql-driver/mysql"
@@ -42,6 +45,7 @@ import (
 
 var db *gorm.DB // Database main variable
 var dictSize uint = 124049
+var botAddr = "bot" // Address of bots instead of ip address, bots aren't shown in statistics
 
 /**
  *
@@ -50,7 +54,8 @@ var dictSize uint = 124049
  *
  * A table containing the basic information about each
  * player: login, password hash, ip address, number of games,
- * wins, points scored, lexicon size.
+ * wins, points scored, lexicon size, Elo rating.
+ * Bots of practice games are users with address "bot".
  */
 type User struct {
 	gorm.Model
@@ -59,9 +64,10 @@ type User struct {
 	Wins       uint   `gorm:"default:0"`
 	Password   uint32
 	IpAddr     string
-	Games      uint `gorm:"default:0"`
-	Scores     uint `gorm:"default:0"`
-	WordsCount uint `gorm:"default:0"`
+	Games      uint    `gorm:"default:0"`
+	Scores     uint    `gorm:"default:0"`
+	WordsCount uint    `gorm:"default:0"`
+	Rating     float64 `gorm:"default:1500"`
 }
 
 /**
@@ -81,14 +87,17 @@ type UserConnection struct {
 /**
  *
  * @class RusWord
- * @brief This table contains a complete dictionary of the Russian language (120,000 words)
+ * @brief This table contains complete dictionaries of all game languages
+ * (120,000 words of the Russian language)
  *
  * A table loading takes 60 seconds when the server starts.
+ * Rows loaded before languages were introduced belong to the Russian language.
  */
 type RusWord struct {
 	gorm.Model
 
 	Word       string `gorm:"type:VARCHAR(100) CHARACTER SET utf8 COLLATE utf8_general_ci"`
+	Language   string `gorm:"type:VARCHAR(16);default:'ru';index"`
 	Popularity uint   `gorm:"default:0"`
 }
 
@@ -97,13 +106,29 @@ type RusWord struct {
  * @class GameSession
  * @brief The table contains information about past, current games and winners.
  *
- * Winner field is null if game is not end yet.
+ * Winner field is null if game is not end yet or it is a team game,
+ * then WinnerTeam contains name of the winning team.
+ * Seed and setup fields are filled when game starts, so the game can be replayed
+ * with its moves (see move.go).
+ * Unranked (practice) games don't change statistics of users.
+ * FinishedAt is null until game is over, it is used by leaderboards of periods (see season.go),
+ * for games finished before the column was added it is restored from UserInGame rows.
  */
 type GameSession struct {
 	gorm.Model
 
-	WinnerID uint
-	Winner   User `gorm:"ForeignKey:WinnerID"`
+	WinnerID    uint
+	Winner      User   `gorm:"ForeignKey:WinnerID"`
+	WinnerTeam  string `gorm:"type:VARCHAR(16)"`
+	Mode        string `gorm:"type:VARCHAR(16);default:'grid'"`
+	Seed        int64  `gorm:"default:0"`
+	Language    string `gorm:"type:VARCHAR(16)"`
+	Ruleset     string `gorm:"type:VARCHAR(32)"`
+	StartPolicy string `gorm:"type:VARCHAR(16)"`
+	StartWord   string `gorm:"type:VARCHAR(100) CHARACTER SET utf8 COLLATE utf8_general_ci"`
+	Players     string `gorm:"type:TEXT"`
+	Ranked      bool   `gorm:"default:true"`
+	FinishedAt  *time.Time
 }
 
 /**
@@ -128,7 +153,7 @@ type UsersLexicon struct {
  *
  * @class UserInGame
  * @brief The table contains the history of the games in which
- * each player was. Also, it has its final score.
+ * each player was. Also, it has its final score and team.
  *
  */
 type UserInGame struct {
@@ -136,6 +161,7 @@ type UserInGame struct {
 
 	UserID      uint
 	Score       uint
+	Team        string `gorm:"type:VARCHAR(16)"`
 	GameID      uint
 	User        User        `gorm:"ForeignKey:UserID"`
 	GameSession GameSession `gorm:"ForeignKey:GameID"`
@@ -178,21 +204,34 @@ func Init(cfg conf.DatabaseConf) error {
 			&GameSession{},
 			&UsersLexicon{},
 			&UserInGame{},
-			&UserConnection{}); res != nil {
+			&UserConnection{},
+			&DictionaryWord{},
+			&WordProposal{},
+			&GameMove{},
+			&CorrGame{},
+			&CorrPlayer{},
+			&DailyResult{},
+			&RatingHistory{},
+			&Tournament{},
+			&TournamentPlayer{},
+			&TournamentGame{},
+			&Season{},
+			&SeasonStanding{}); res != nil {
 		return res.Error
 	}
-	return nil
+	return backfillFinishedAt()
 }
 
 /**
  *
- * @brief Loading the dictionary of the Russian language.
+ * @brief Loading the dictionary of the language.
+ * @param[in] language name of dictionary.
  * @param[in] path to dictionary txt file.
  * @return error
  *
  * Uploading takes around of 1 minute
  */
-func LoadDictionary(path string) error {
+func LoadDictionary(language string, path string) error {
 
 	file, err := os.Open(path)
 	defer file.Close()
@@ -203,11 +242,11 @@ func LoadDictionary(path string) error {
 
 	//scanner := bufio.NewScanner(file)
 
-	logger.Log.Info("Loading dictionary. Please wait... (approximately 60 seconds)")
+	logger.Log.Info(fmt.Sprintf("Loading dictionary '%s'. Please wait... (approximately 60 seconds)", language))
 
 	//for scanner.Scan() {
 	//	dictSize++
-	//	var word = RusWord{Word: string(scanner.Text())}
+	//	var word = RusWord{Word: string(scanner.Text()), Language: language}
 	//	if res := db.Create(&word); res.Error != nil {
 	//		return res.Error
 	//	}
@@ -252,6 +291,30 @@ func AddUser(username string, password string, ip string) (*User, error) {
 	return &newUser, nil
 }
 
+/**
+ *
+ * @brief Add bot player to db if it doesn't exist.
+ * @param[in] username of bot
+ * @return the record of bot.
+ * @return error
+ *
+ * Bot can't log in: its password is unknown.
+ */
+func AddBot(username string) (*User, error) {
+
+	bot := User{}
+	if res := db.
+		Where(User{Name: username}).
+		Attrs(User{Password: hash(fmt.Sprintf("%s%d", username, time.Now().UnixNano())), IpAddr: botAddr}).
+		FirstOrCreate(&bot); res.Error != nil {
+		return nil, res.Error
+	}
+	if bot.IpAddr != botAddr {
+		return nil, errors.New(fmt.Sprintf("Name '%s' is taken by user", username))
+	}
+	return &bot, nil
+}
+
 /**
  *
  * @brief Valid username - password login.
@@ -297,6 +360,18 @@ func AddUserConnection(username string, ip string) (*UserConnection, error) {
 	return &newUserConnection, nil
 }
 
+/**
+ *
+ * @brief Predicate, check if query failed because record isn't found.
+ * @param[in] err error of query
+ * @return true if record isn't found
+ *
+ */
+func NotFound(err error) bool {
+
+	return err == gorm.ErrRecordNotFound
+}
+
 /**
  *
  * @brief Create new game with empty winner.
@@ -371,19 +446,20 @@ func RemoveUserFromSession(username string) (*UserInGame, error) {
  * @brief Adds word to user's personal lexicon vocabulary.
  * @param[in] username of user
  * @param[in] new word
+ * @param[in] language of word
  * @return the record just created for the new user's word.
  * @return error
  *
  * Increments userslexicon value if word was used already
  */
-func AddWord(username string, word string) (*UsersLexicon, error) {
+func AddWord(username string, word string, language string) (*UsersLexicon, error) {
 
 	user := User{}
 	if res := db.Where("name = ?", username).First(&user); res.Error != nil {
 		return nil, res.Error
 	}
 	rusWord := RusWord{}
-	if res := db.Where("word = ?", word).First(&rusWord); res.Error != nil {
+	if res := db.Where("word = ? and language = ?", word, language).First(&rusWord); res.Error != nil {
 		return nil, res.Error
 	}
 
@@ -416,56 +492,223 @@ func AddWord(username string, word string) (*UsersLexicon, error) {
 	return &userLexicon, nil
 }
 
+/**
+ *
+ * @brief Removes word from user's personal lexicon vocabulary.
+ * @param[in] username of user
+ * @param[in] word to remove
+ * @param[in] language of word
+ * @return error
+ *
+ * Reverts AddWord when the move is rolled back:
+ * decrements userslexicon value or deletes the record if word was used once
+ */
+func RemoveWord(username string, word string, language string) error {
+
+	user := User{}
+	if res := db.Where("name = ?", username).First(&user); res.Error != nil {
+		return res.Error
+	}
+	rusWord := RusWord{}
+	if res := db.Where("word = ? and language = ?", word, language).First(&rusWord); res.Error != nil {
+		return res.Error
+	}
+
+	if rusWord.Popularity > 0 {
+		rusWord.Popularity--
+	}
+
+	userLexicon := UsersLexicon{}
+	if res := db.
+		Where("user_id = ? and rus_word_id = ?", user.ID, rusWord.ID).
+		First(&userLexicon); res.Error != nil {
+		return res.Error
+	}
+
+	if userLexicon.Count <= 1 {
+		if res := db.Delete(&userLexicon); res.Error != nil {
+			return res.Error
+		}
+
+		if user.WordsCount > 0 {
+			user.WordsCount--
+		}
+		if res := db.Save(&user); res.Error != nil {
+			return res.Error
+		}
+
+	} else {
+		userLexicon.Count--
+		if res := db.Save(&userLexicon); res.Error != nil {
+			return res.Error
+		}
+	}
+	if res := db.Save(&rusWord); res.Error != nil {
+		return res.Error
+	}
+	return nil
+}
+
+/**
+ *
+ * @class GameResult
+ * @brief Final statistics of the game.
+ *
+ * Teams and WinnerTeam are empty if game is played without teams.
+ */
+type GameResult struct {
+	Scores     map[string]int    ///< Score by username
+	Teams      map[string]string ///< Team by username
+	Winners    []string          ///< Usernames of winners (all members of the winning team), empty if it is a draw
+	WinnerTeam string            ///< Name of the winning team
+}
+
 /**
  *
  * @brief Ends the game and earns points.
- * @param[in] game final statistics which contains players scores and info about winner.
  * @param[in] game session id returned from start game method
+ * @param[in] game final statistics which contains players scores, teams and info about winners.
  * @return error
  *
  * for all players scores += this game scores
  * for all players games ++
- * for winner wins ++
+ * for winners wins ++
+ * for all players rating is updated by Elo (see rating.go)
+ * Unranked games keep only scores of players in this game and the winner.
+ * All changes are made in one transaction, rows of players are locked until it ends,
+ * so games of the same player can finish at the same time.
  */
-func GameOver(gameStatistics map[string]int, gameID uint, winner string) error {
+func GameOver(gameID uint, result GameResult) error {
 
-	for key, value := range gameStatistics {
+	tx := db.Begin()
+	gameSession := GameSession{}
+	if res := tx.Set("gorm:query_option", "FOR UPDATE").Where("id = ?", gameID).First(&gameSession); res.Error != nil {
+		tx.Rollback()
+		return res.Error
+	}
+
+	names := []string{}
+	for name := range result.Scores {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	users := make(map[string]*User)
+	for _, key := range names {
+		value := result.Scores[key]
 
 		user := User{}
-		if res := db.Where("name = ?", key).First(&user); res.Error != nil {
+		if res := tx.Set("gorm:query_option", "FOR UPDATE").Where("name = ?", key).First(&user); res.Error != nil {
+			tx.Rollback()
 			return res.Error
 		}
-		user.Games++
-		user.Scores += uint(value)
-
-		if key == winner {
-			user.Wins++
-			gameSession := GameSession{}
-			if res := db.Where("id = ?", gameID).First(&gameSession); res.Error != nil {
-				return res.Error
-			}
-			gameSession.WinnerID = user.ID
-			if res := db.Save(&gameSession); res.Error != nil {
-				return res.Error
+		if gameSession.Ranked {
+			user.Games++
+			user.Scores += uint(value)
+		}
+
+		for _, winner := range result.Winners {
+			if key == winner {
+				if gameSession.Ranked {
+					user.Wins++
+				}
+				if result.WinnerTeam == "" {
+					gameSession.WinnerID = user.ID
+				}
 			}
 		}
 
 		userInGame := UserInGame{}
-		if res := db.
+		if res := tx.
 			Where("user_id = ? and game_id = ?", user.ID, gameID).
 			First(&userInGame); res.Error != nil {
+			tx.Rollback()
 			return res.Error
 		}
 		userInGame.Score = uint(value)
-		if res := db.Save(&userInGame); res.Error != nil {
+		userInGame.Team = result.Teams[key]
+		if res := tx.Save(&userInGame); res.Error != nil {
+			tx.Rollback()
 			return res.Error
 		}
-		if res := db.Save(&user); res.Error != nil {
+		users[key] = &user
+
+	}
+
+	if gameSession.Ranked {
+		if err := updateRatings(tx, gameID, users, result); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	for _, user := range users {
+		if res := tx.Save(user); res.Error != nil {
+			tx.Rollback()
 			return res.Error
 		}
+	}
 
+	finishedAt := time.Now()
+	gameSession.WinnerTeam = result.WinnerTeam
+	gameSession.FinishedAt = &finishedAt
+	if res := tx.Save(&gameSession); res.Error != nil {
+		tx.Rollback()
+		return res.Error
 	}
-	return nil
+	return tx.Commit().Error
+}
+
+/**
+ *
+ * @brief Returns ratings of players.
+ * @param[in] usernames of players
+ * @return map[username of player]Elo rating (initial rating for unknown players)
+ * @return error
+ *
+ */
+func Ratings(usernames []string) (map[string]float64, error) {
+
+	users := []User{}
+	if res := db.Where("name in (?)", usernames).Find(&users); res.Error != nil {
+		return nil, res.Error
+	}
+
+	ratings := make(map[string]float64)
+	for _, name := range usernames {
+		ratings[name] = eloInitial
+	}
+	for i := range users {
+		ratings[users[i].Name] = users[i].Rating
+	}
+	return ratings, nil
+}
+
+/**
+ *
+ * @brief Returns names of users, which are not registered.
+ * @param[in] usernames of users
+ * @return slice of unknown names in the same order
+ * @return error
+ *
+ */
+func UnknownUsers(usernames []string) ([]string, error) {
+
+	users := []User{}
+	if res := db.Where("name in (?)", usernames).Find(&users); res.Error != nil {
+		return nil, res.Error
+	}
+
+	known := make(map[string]bool)
+	for i := range users {
+		known[users[i].Name] = true
+	}
+	unknown := []string{}
+	for _, name := range usernames {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	return unknown, nil
 }
 
 /**
@@ -490,23 +733,25 @@ func normalizeLimitAndOrder(lenOfTable uint, limit *uint, offset *uint) {
 /**
  *
  * @brief Get top of users by one of their fields.
- * @param[in] mode of sorting (scores, games, wins)
+ * @param[in] mode of sorting (scores, games, wins, rating)
  * @param[in] limit
  * @param[in] offset
  * @return slice of users objects
  * @return error
  *
+ * Bots aren't shown.
  */
 func GetTop(mode string, limit uint, offset uint) ([]User, error) {
 
 	top := []User{}
 
-	if res := db.Order(fmt.Sprintf("%s desc", mode)).Find(&top); res.Error != nil {
+	if res := db.Where("ip_addr <> ?", botAddr).Order(fmt.Sprintf("%s desc", mode)).Find(&top); res.Error != nil {
 		return nil, res.Error
 	}
 	normalizeLimitAndOrder(uint(len(top)), &limit, &offset)
 
 	if res := db.
+		Where("ip_addr <> ?", botAddr).
 		Order(fmt.Sprintf("%s desc", mode)).
 		Limit(limit).
 		Offset(offset).
@@ -541,6 +786,32 @@ func TopWords(limit uint, offset uint) ([]RusWord, error) {
 	return top, nil
 }
 
+/**
+ *
+ * @brief Returns popularity of words.
+ * @param[in] language of words
+ * @param[in] words
+ * @return map[word]number of uses by players (0 for words, which aren't found)
+ * @return error
+ *
+ */
+func WordsPopularity(language string, words []string) (map[string]uint, error) {
+
+	rusWords := []RusWord{}
+	if res := db.Where("language = ? and word in (?)", language, words).Find(&rusWords); res.Error != nil {
+		return nil, res.Error
+	}
+
+	popularity := make(map[string]uint)
+	for _, w := range words {
+		popularity[w] = 0
+	}
+	for i := range rusWords {
+		popularity[rusWords[i].Word] = rusWords[i].Popularity
+	}
+	return popularity, nil
+}
+
 /**
  *
  * @brief Return the top players who most often use this word.
@@ -550,6 +821,7 @@ func TopWords(limit uint, offset uint) ([]RusWord, error) {
  * @return map[username of player]number of word uses
  * @return error
  *
+ * Bots aren't shown.
  */
 func WordTopUsers(word string, limit uint, offset uint) (map[string]uint, error) {
 
@@ -560,13 +832,15 @@ func WordTopUsers(word string, limit uint, offset uint) (map[string]uint, error)
 		return nil, res.Error
 	}
 
-	if res := db.Where("rus_word_id = ? ", rusWordField.ID).Find(&topLexicons); res.Error != nil {
+	notBot := "user_id NOT IN (SELECT id FROM users WHERE ip_addr = ?)"
+	if res := db.Where("rus_word_id = ? ", rusWordField.ID).Where(notBot, botAddr).Find(&topLexicons); res.Error != nil {
 		return nil, res.Error
 	}
 	normalizeLimitAndOrder(uint(len(topLexicons)), &limit, &offset)
 
 	if res := db.
 		Where("rus_word_id = ? ", rusWordField.ID).
+		Where(notBot, botAddr).
 		Order("count DESC").
 		Limit(limit).
 		Offset(offset).
@@ -622,8 +896,11 @@ func CurrentGameUsersList(username string) ([]User, error) {
 }
 
 type gameFullStat struct {
-	Winner string
-	Users  []User
+	Ranked     bool
+	Winner     string
+	WinnerTeam string
+	Users      []User
+	Teams      map[string]string
 }
 
 /**
@@ -643,7 +920,11 @@ func UserAllGamesStat(username string, limit uint, offset uint) (map[uint]gameFu
 		return nil, res.Error
 	}
 
-	normalizeLimitAndOrder(user.Games, &limit, &offset)
+	var gamesCount uint
+	if res := db.Model(&UserInGame{}).Where("user_id = ?", user.ID).Count(&gamesCount); res.Error != nil {
+		return nil, res.Error
+	}
+	normalizeLimitAndOrder(gamesCount, &limit, &offset)
 
 	userGamesList := []UserInGame{}
 	if res := db.
@@ -667,10 +948,17 @@ func UserAllGamesStat(username string, limit uint, offset uint) (map[uint]gameFu
 		}
 
 		usersList := []User{}
+		teams := make(map[string]string)
 		for j := range anotherUsersInThisGame {
 			usersList = append(usersList, anotherUsersInThisGame[j].User)
+			teams[anotherUsersInThisGame[j].User.Name] = anotherUsersInThisGame[j].Team
 		}
-		result[userGamesList[i].GameID] = gameFullStat{Winner: userGamesList[i].GameSession.Winner.Name, Users: usersList}
+		result[userGamesList[i].GameID] = gameFullStat{
+			Ranked:     userGamesList[i].GameSession.Ranked,
+			Winner:     userGamesList[i].GameSession.Winner.Name,
+			WinnerTeam: userGamesList[i].GameSession.WinnerTeam,
+			Users:      usersList,
+			Teams:      teams}
 
 	}
 	return result, nil
@@ -0,0 +1,117 @@
+/**
+ *
+ * @file dictionary.go
+ * @brief Database
+ *
+ * Runtime changes of game dictionaries
+ */
+
+package db
+
+import (
+	// System
+
+	// Third-party
+	"github.com/jinzhu/gorm"
+	// Project
+)
+
+/**
+ *
+ * @class DictionaryWord
+ * @brief The table contains words added, removed or banned by admins.
+ *
+ * Words are layered over dictionary files when the server starts.
+ * Every word has the only record with the last action.
+ */
+type DictionaryWord struct {
+	gorm.Model
+
+	Language string `gorm:"type:VARCHAR(16);index"`
+	Word     string `gorm:"type:VARCHAR(100) CHARACTER SET utf8 COLLATE utf8_general_ci"`
+	Action   string `gorm:"type:VARCHAR(16)"`
+	AuthorID uint
+	Author   User `gorm:"ForeignKey:AuthorID"`
+}
+
+/**
+ *
+ * @brief Saves the last action of admin with the word.
+ * @param[in] language of word
+ * @param[in] word
+ * @param[in] action (add, remove, ban)
+ * @param[in] username of admin
+ * @return the record of the word.
+ * @return error
+ *
+ */
+func SetDictionaryWord(language string, word string, action string, username string) (*DictionaryWord, error) {
+
+	user := User{}
+	if res := db.Where("name = ?", username).First(&user); res.Error != nil {
+		return nil, res.Error
+	}
+	return setDictionaryWord(db, language, word, action, user.ID)
+}
+
+/**
+ *
+ * @brief Saves the last action of admin with the word in transaction.
+ * @param[in] tx transaction or database
+ * @param[in] language of word
+ * @param[in] word
+ * @param[in] action (add, remove, ban)
+ * @param[in] authorID id of admin
+ * @return the record of the word.
+ * @return error
+ *
+ */
+func setDictionaryWord(tx *gorm.DB, language string, word string, action string, authorID uint) (*DictionaryWord, error) {
+
+	dictWord := DictionaryWord{}
+	if res := tx.
+		Where("language = ? and word = ?", language, word).
+		First(&dictWord); res.Error != nil {
+		dictWord = DictionaryWord{Language: language, Word: word}
+	}
+
+	dictWord.Action = action
+	dictWord.AuthorID = authorID
+	if res := tx.Save(&dictWord); res.Error != nil {
+		return nil, res.Error
+	}
+
+	if action == "add" {
+		rusWord := RusWord{}
+		if res := tx.Where("word = ? and language = ?", word, language).First(&rusWord); res.Error != nil {
+			rusWord = RusWord{Word: word, Language: language}
+			if res := tx.Create(&rusWord); res.Error != nil {
+				return nil, res.Error
+			}
+		}
+	}
+
+	return &dictWord, nil
+}
+
+/**
+ *
+ * @brief Returns all runtime changes of the dictionary.
+ * @param[in] language of dictionary
+ * @return map[word]action
+ * @return error
+ *
+ */
+func DictionaryWords(language string) (map[string]string, error) {
+
+	words := []DictionaryWord{}
+	if res := db.Where("language = ?", language).Find(&words); res.Error != nil {
+		return nil, res.Error
+	}
+
+	result := make(map[string]string)
+	for i := range words {
+		result[words[i].Word] = words[i].Action
+	}
+	return result, nil
+}
@@ -0,0 +1,143 @@
+/**
+ *
+ * @file move.go
+ * @brief Database
+ *
+ * Setup and moves of games, which are used to replay them
+ */
+
+package db
+
+import (
+	// System
+	"strings"
+
+	// Third-party
+	"github.com/jinzhu/gorm"
+	// Project
+)
+
+/**
+ * @brief enum of move kinds
+ */
+const (
+	MovePut  = "put"  ///< Player put letter and made word
+	MoveSkip = "skip" ///< Player skipped
+	MoveWord = "word" ///< Player found word (hunt mode)
+	MoveUndo = "undo" ///< Previous move was rolled back after challenge
+)
+
+/**
+ *
+ * @class GameMove
+ * @brief The table contains the log of moves of every game.
+ *
+ * Moves are numbered from 1 in order they were made.
+ * Coordinates, letter and word are empty for skips.
+ */
+type GameMove struct {
+	gorm.Model
+
+	GameID      uint `gorm:"index"`
+	Number      uint
+	Kind        string `gorm:"type:VARCHAR(16)"`
+	X           int
+	Y           int
+	Letter      string `gorm:"type:VARCHAR(4) CHARACTER SET utf8 COLLATE utf8_general_ci"`
+	Word        string `gorm:"type:VARCHAR(100) CHARACTER SET utf8 COLLATE utf8_general_ci"`
+	UserID      uint
+	User        User        `gorm:"ForeignKey:UserID"`
+	GameSession GameSession `gorm:"ForeignKey:GameID"`
+}
+
+/**
+ *
+ * @brief Saves setup of the started game.
+ * @param[in] game session id returned from start game method
+ * @param[in] mode of the game (grid, classic)
+ * @param[in] seed of random generator of the game
+ * @param[in] language of the game
+ * @param[in] ruleset of the game
+ * @param[in] start policy which chose start word
+ * @param[in] start word
+ * @param[in] players in order of their moves
+ * @param[in] ranked flag, false for practice games
+ * @return error
+ *
+ */
+func SetupGame(gameID uint, mode string, seed int64, language string, ruleset string, startPolicy string, startWord string, players []string, ranked bool) error {
+
+	gameSession := GameSession{}
+	if res := db.Where("id = ?", gameID).First(&gameSession); res.Error != nil {
+		return res.Error
+	}
+
+	gameSession.Mode = mode
+	gameSession.Seed = seed
+	gameSession.Language = language
+	gameSession.Ruleset = ruleset
+	gameSession.StartPolicy = startPolicy
+	gameSession.StartWord = startWord
+	gameSession.Players = strings.Join(players, ",")
+	gameSession.Ranked = ranked
+	if res := db.Save(&gameSession); res.Error != nil {
+		return res.Error
+	}
+	return nil
+}
+
+/**
+ *
+ * @brief Adds move to the log of the game.
+ * @param[in] game session id returned from start game method
+ * @param[in] number of move in the game
+ * @param[in] username of player
+ * @param[in] kind of move (put, skip)
+ * @param[in] x coordinate of letter (column)
+ * @param[in] y coordinate of letter (row)
+ * @param[in] letter
+ * @param[in] word
+ * @return the record just created for the new move.
+ * @return error
+ *
+ */
+func AddMove(gameID uint, number uint, username string, kind string, x int, y int, letter string, word string) (*GameMove, error) {
+
+	user := User{}
+	if res := db.Where("name = ?", username).First(&user); res.Error != nil {
+		return nil, res.Error
+	}
+
+	move := GameMove{GameID: gameID, Number: number, Kind: kind, X: x, Y: y, Letter: letter, Word: word, UserID: user.ID}
+	if res := db.Create(&move); res.Error != nil {
+		return nil, res.Error
+	}
+	return &move, nil
+}
+
+/**
+ *
+ * @brief Returns setup and moves of the game.
+ * @param[in] game session id
+ * @return game session record
+ * @return moves in order they were made
+ * @return error
+ *
+ */
+func GameRecord(gameID uint) (*GameSession, []GameMove, error) {
+
+	gameSession := GameSession{}
+	if res := db.Where("id = ?", gameID).First(&gameSession); res.Error != nil {
+		return nil, nil, res.Error
+	}
+
+	moves := []GameMove{}
+	if res := db.
+		Where("game_id = ?", gameID).
+		Order("number").
+		Preload("User").
+		Find(&moves); res.Error != nil {
+		return nil, nil, res.Error
+	}
+	return &gameSession, moves, nil
+}
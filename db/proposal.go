@@ -0,0 +1,169 @@
+/**
+ *
+ * @file proposal.go
+ * @brief Database
+ *
+ * Queue of words proposed by players
+ */
+
+package db
+
+import (
+	// System
+
+	// Third-party
+	"github.com/jinzhu/gorm"
+	// Project
+)
+
+/**
+ * @brief enum of proposal statuses
+ */
+const (
+	ProposalPending  = "pending"  ///< Proposal waits for admin
+	ProposalApproved = "approved" ///< Word is added to dictionary
+	ProposalRejected = "rejected" ///< Word is not added to dictionary
+)
+
+/**
+ *
+ * @class WordProposal
+ * @brief The table contains words, which players want to add to dictionary.
+ *
+ * Admins approve or reject them.
+ */
+type WordProposal struct {
+	gorm.Model
+
+	Language    string `gorm:"type:VARCHAR(16);index"`
+	Word        string `gorm:"type:VARCHAR(100) CHARACTER SET utf8 COLLATE utf8_general_ci"`
+	Status      string `gorm:"type:VARCHAR(16);index"`
+	UserID      uint
+	ModeratorID uint
+	User        User `gorm:"ForeignKey:UserID"`
+	Moderator   User `gorm:"ForeignKey:ModeratorID"`
+}
+
+/**
+ *
+ * @brief Adds the word to the queue of proposals.
+ * @param[in] language of word
+ * @param[in] word
+ * @param[in] username of player
+ * @return the record just created for the new proposal.
+ * @return pending is true if the word is already in the queue (record is not created then)
+ * @return error
+ *
+ */
+func AddProposal(language string, word string, username string) (*WordProposal, bool, error) {
+
+	proposal := WordProposal{}
+	if res := db.
+		Where("language = ? and word = ? and status = ?", language, word, ProposalPending).
+		First(&proposal); res.Error == nil {
+		return &proposal, true, nil
+	}
+
+	user := User{}
+	if res := db.Where("name = ?", username).First(&user); res.Error != nil {
+		return nil, false, res.Error
+	}
+
+	proposal = WordProposal{Language: language, Word: word, Status: ProposalPending, UserID: user.ID}
+	if res := db.Create(&proposal); res.Error != nil {
+		return nil, false, res.Error
+	}
+	return &proposal, false, nil
+}
+
+/**
+ *
+ * @brief Returns the oldest proposals waiting for admin.
+ * @param[in] limit
+ * @return slice of proposals with users
+ * @return error
+ *
+ */
+func PendingProposals(limit uint) ([]WordProposal, error) {
+
+	proposals := []WordProposal{}
+	if res := db.
+		Where("status = ?", ProposalPending).
+		Preload("User").
+		Order("id").
+		Limit(limit).
+		Find(&proposals); res.Error != nil {
+		return nil, res.Error
+	}
+	return proposals, nil
+}
+
+/**
+ *
+ * @brief Returns the pending proposal.
+ * @param[in] id of proposal
+ * @return the proposal with user.
+ * @return error
+ *
+ */
+func PendingProposal(id uint) (*WordProposal, error) {
+
+	proposal := WordProposal{}
+	if res := db.
+		Where("id = ? and status = ?", id, ProposalPending).
+		Preload("User").
+		First(&proposal); res.Error != nil {
+		return nil, res.Error
+	}
+	return &proposal, nil
+}
+
+/**
+ *
+ * @brief Approves or rejects the proposal.
+ * @param[in] id of proposal
+ * @param[in] status (approved or rejected)
+ * @param[in] username of admin
+ * @return the changed proposal.
+ * @return error
+ *
+ * Only pending proposals can be resolved.
+ * Approved word is saved as added to the dictionary in the same transaction.
+ */
+func ResolveProposal(id uint, status string, username string) (*WordProposal, error) {
+
+	moderator := User{}
+	if res := db.Where("name = ?", username).First(&moderator); res.Error != nil {
+		return nil, res.Error
+	}
+
+	tx := db.Begin()
+	proposal := WordProposal{}
+	if res := tx.
+		Set("gorm:query_option", "FOR UPDATE").
+		Where("id = ? and status = ?", id, ProposalPending).
+		Preload("User").
+		First(&proposal); res.Error != nil {
+		tx.Rollback()
+		return nil, res.Error
+	}
+
+	proposal.Status = status
+	proposal.ModeratorID = moderator.ID
+	if res := tx.Set("gorm:save_associations", false).Save(&proposal); res.Error != nil {
+		tx.Rollback()
+		return nil, res.Error
+	}
+
+	if status == ProposalApproved {
+		if _, err := setDictionaryWord(tx, proposal.Language, proposal.Word, "add", moderator.ID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return &proposal, nil
+}
@@ -0,0 +1,145 @@
+/**
+ *
+ * @file rating.go
+ * @brief Database
+ *
+ * Elo rating of players. Multiplayer game is split into pairs of players:
+ * every pair is a duel decided by scores (team scores in team game, teammates aren't paired),
+ * change of rating is the mean of changes in all duels of player.
+ */
+
+package db
+
+import (
+	// System
+	"math"
+	"sort"
+
+	// Third-party
+	"github.com/jinzhu/gorm"
+	// Project
+)
+
+const (
+	eloInitial = 1500 ///< Rating of new player
+	eloK       = 32   ///< Maximum change of rating in one game
+	eloScale   = 400  ///< Difference of ratings, which gives 10:1 odds
+)
+
+/**
+ *
+ * @class RatingHistory
+ * @brief The table contains rating of player before and after every ranked game.
+ *
+ */
+type RatingHistory struct {
+	gorm.Model
+
+	UserID uint
+	GameID uint
+	Before float64
+	After  float64
+	User   User `gorm:"ForeignKey:UserID"`
+}
+
+/**
+ *
+ * @brief Expected result of duel by Elo.
+ * @param[in] rating of player
+ * @param[in] rating of opponent
+ * @return probability of win of player
+ *
+ */
+func eloExpected(rating float64, opponent float64) float64 {
+	return 1 / (1 + math.Pow(10, (opponent-rating)/eloScale))
+}
+
+/**
+ *
+ * @brief Updates ratings of players after the game and saves rating history.
+ * @param[in] transaction of GameOver
+ * @param[in] game session id
+ * @param[in] map[username]player, ratings are changed but not saved
+ * @param[in] game final statistics
+ * @return error
+ *
+ */
+func updateRatings(tx *gorm.DB, gameID uint, users map[string]*User, result GameResult) error {
+
+	names := []string{}
+	for name := range users {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	points := make(map[string]int)
+	for _, name := range names {
+		if team := result.Teams[name]; team != "" {
+			for member, score := range result.Scores {
+				if result.Teams[member] == team {
+					points[name] += score
+				}
+			}
+		} else {
+			points[name] = result.Scores[name]
+		}
+	}
+
+	after := make(map[string]float64)
+	for _, name := range names {
+		sum, duels := 0.0, 0
+		for _, opponent := range names {
+			if opponent == name || (result.Teams[name] != "" && result.Teams[name] == result.Teams[opponent]) {
+				continue
+			}
+			outcome := 0.5
+			if points[name] > points[opponent] {
+				outcome = 1
+			} else if points[name] < points[opponent] {
+				outcome = 0
+			}
+			sum += outcome - eloExpected(users[name].Rating, users[opponent].Rating)
+			duels++
+		}
+		after[name] = users[name].Rating
+		if duels > 0 {
+			after[name] += eloK * sum / float64(duels)
+		}
+	}
+
+	for _, name := range names {
+		history := RatingHistory{UserID: users[name].ID, GameID: gameID, Before: users[name].Rating, After: after[name]}
+		if res := tx.Create(&history); res.Error != nil {
+			return res.Error
+		}
+		users[name].Rating = after[name]
+	}
+	return nil
+}
+
+/**
+ *
+ * @brief Returns rating history of player.
+ * @param[in] username of player
+ * @param[in] limit of records
+ * @return records from the last game
+ * @return error
+ *
+ */
+func UserRatingHistory(username string, limit uint) ([]RatingHistory, error) {
+
+	user := User{}
+	if res := db.Where("name = ? and ip_addr <> ?", username, botAddr).First(&user); res.Error != nil {
+		return nil, res.Error
+	}
+
+	history := []RatingHistory{}
+	if res := db.
+		Where("user_id = ?", user.ID).
+		Order("id desc").
+		Limit(limit).
+		Find(&history); res.Error != nil {
+		return nil, res.Error
+	}
+	return history, nil
+}
@@ -0,0 +1,292 @@
+/**
+ *
+ * @file season.go
+ * @brief Database
+ *
+ * Leaderboards of time periods, they are computed from UserInGame rows of ranked games
+ * finished in period. Seasons are named periods, final standings of season are archived.
+ * Finish time of games played before it was saved is restored from UserInGame rows.
+ */
+
+package db
+
+import (
+	// System
+	"errors"
+	"fmt"
+	"time"
+
+	// Third-party
+	"github.com/jinzhu/gorm"
+	// Project
+)
+
+/**
+ *
+ * @class Season
+ * @brief The table contains seasons: named periods of leaderboards.
+ *
+ * Games finished since Start and before End count in season.
+ */
+type Season struct {
+	gorm.Model
+
+	Name     string `gorm:"type:VARCHAR(100) CHARACTER SET utf8 COLLATE utf8_general_ci;unique"`
+	Start    time.Time
+	End      time.Time
+	Archived bool `gorm:"default:false"`
+}
+
+/**
+ *
+ * @class SeasonStanding
+ * @brief The table contains archived final standings of seasons.
+ *
+ */
+type SeasonStanding struct {
+	gorm.Model
+
+	SeasonID uint `gorm:"index"`
+	UserID   uint
+	Place    uint
+	Scores   uint
+	Games    uint
+	Wins     uint
+	User     User `gorm:"ForeignKey:UserID"`
+}
+
+/**
+ *
+ * @class PeriodStat
+ * @brief Statistics of user in period.
+ *
+ */
+type PeriodStat struct {
+	Name   string
+	Scores uint
+	Games  uint
+	Wins   uint
+}
+
+/**
+ *
+ * @brief Returns column of PeriodStat by mode of sorting.
+ * @param[in] mode of sorting (scores, games, wins)
+ * @return column name
+ * @return error if mode is unknown
+ *
+ */
+func periodColumn(mode string) (string, error) {
+
+	switch mode {
+	case "score", "scores":
+		return "scores", nil
+	case "games", "wins":
+		return mode, nil
+	}
+	return "", errors.New(fmt.Sprintf("Mode '%s' can't be used for period", mode))
+}
+
+/**
+ *
+ * @brief Sets finish time of games finished before it was saved.
+ * @return error
+ *
+ * Scores of players are saved into UserInGame rows when game is over, so the latest
+ * update of them is the finish time. Rows of unfinished games aren't updated after creation.
+ */
+func backfillFinishedAt() error {
+
+	if res := db.Exec(`UPDATE game_sessions SET finished_at = (
+			SELECT MAX(uig.updated_at) FROM user_in_games uig
+			WHERE uig.game_id = game_sessions.id AND uig.deleted_at IS NULL AND uig.updated_at > uig.created_at)
+		WHERE finished_at IS NULL AND EXISTS (
+			SELECT 1 FROM user_in_games uig
+			WHERE uig.game_id = game_sessions.id AND uig.deleted_at IS NULL AND uig.updated_at > uig.created_at)`); res.Error != nil {
+		return res.Error
+	}
+	return nil
+}
+
+/**
+ *
+ * @brief Get top of users by games finished in period.
+ * @param[in] mode of sorting (scores, games, wins)
+ * @param[in] start of period
+ * @param[in] end of period
+ * @param[in] limit
+ * @param[in] offset
+ * @return statistics of users
+ * @return error
+ *
+ * Only ranked games count, bots aren't shown. Member of the winning team wins too.
+ */
+func PeriodTop(mode string, start time.Time, end time.Time, limit uint, offset uint) ([]PeriodStat, error) {
+
+	column, err := periodColumn(mode)
+	if err != nil {
+		return nil, err
+	}
+
+	top := []PeriodStat{}
+	if res := db.
+		Table("user_in_games").
+		Select(`users.name AS name,
+			SUM(user_in_games.score) AS scores,
+			COUNT(*) AS games,
+			SUM(CASE WHEN game_sessions.winner_id = users.id OR
+				(game_sessions.winner_team <> '' AND game_sessions.winner_team = user_in_games.team)
+				THEN 1 ELSE 0 END) AS wins`).
+		Joins("JOIN game_sessions ON game_sessions.id = user_in_games.game_id").
+		Joins("JOIN users ON users.id = user_in_games.user_id").
+		Where("user_in_games.deleted_at IS NULL and game_sessions.ranked = ? and game_sessions.finished_at >= ? and game_sessions.finished_at < ? and users.ip_addr <> ?",
+			true, start, end, botAddr).
+		Group("users.id, users.name").
+		Order(fmt.Sprintf("%s desc, name", column)).
+		Limit(limit).
+		Offset(offset).
+		Scan(&top); res.Error != nil {
+		return nil, res.Error
+	}
+	return top, nil
+}
+
+/**
+ *
+ * @brief Creates season.
+ * @param[in] name of season
+ * @param[in] start of season
+ * @param[in] end of season
+ * @return the record just created for the new season.
+ * @return error
+ *
+ */
+func CreateSeason(name string, start time.Time, end time.Time) (*Season, error) {
+
+	season := Season{Name: name, Start: start, End: end}
+	if res := db.Create(&season); res.Error != nil {
+		return nil, res.Error
+	}
+	return &season, nil
+}
+
+/**
+ *
+ * @brief Returns season by name.
+ * @param[in] name of season
+ * @return season record
+ * @return error
+ *
+ */
+func SeasonByName(name string) (*Season, error) {
+
+	season := Season{}
+	if res := db.Where("name = ?", name).First(&season); res.Error != nil {
+		return nil, res.Error
+	}
+	return &season, nil
+}
+
+/**
+ *
+ * @brief Returns all seasons.
+ * @return seasons from the latest one
+ * @return error
+ *
+ */
+func Seasons() ([]Season, error) {
+
+	seasons := []Season{}
+	if res := db.Order("start desc").Find(&seasons); res.Error != nil {
+		return nil, res.Error
+	}
+	return seasons, nil
+}
+
+/**
+ *
+ * @brief Saves final standings of season and marks it archived.
+ * @param[in] season record
+ * @return number of archived users
+ * @return error
+ *
+ * Standings are sorted by scores. All changes are made in one transaction.
+ */
+func ArchiveSeason(season *Season) (int, error) {
+
+	top, err := PeriodTop("scores", season.Start, season.End, ^uint(0)>>1, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	users := []User{}
+	names := []string{}
+	for i := range top {
+		names = append(names, top[i].Name)
+	}
+	if res := db.Where("name in (?)", names).Find(&users); res.Error != nil {
+		return 0, res.Error
+	}
+	ids := make(map[string]uint)
+	for i := range users {
+		ids[users[i].Name] = users[i].ID
+	}
+
+	tx := db.Begin()
+	for i := range top {
+		standing := SeasonStanding{
+			SeasonID: season.ID,
+			UserID:   ids[top[i].Name],
+			Place:    uint(i + 1),
+			Scores:   top[i].Scores,
+			Games:    top[i].Games,
+			Wins:     top[i].Wins,
+		}
+		if res := tx.Create(&standing); res.Error != nil {
+			tx.Rollback()
+			return 0, res.Error
+		}
+	}
+	season.Archived = true
+	if res := tx.Save(season); res.Error != nil {
+		tx.Rollback()
+		return 0, res.Error
+	}
+	return len(top), tx.Commit().Error
+}
+
+/**
+ *
+ * @brief Get top of users from archived standings of season.
+ * @param[in] season id
+ * @param[in] mode of sorting (scores, games, wins)
+ * @param[in] limit
+ * @param[in] offset
+ * @return statistics of users
+ * @return error
+ *
+ */
+func SeasonTop(seasonID uint, mode string, limit uint, offset uint) ([]PeriodStat, error) {
+
+	column, err := periodColumn(mode)
+	if err != nil {
+		return nil, err
+	}
+
+	standings := []SeasonStanding{}
+	if res := db.
+		Where("season_id = ?", seasonID).
+		Order(fmt.Sprintf("%s desc, place", column)).
+		Limit(limit).
+		Offset(offset).
+		Preload("User").
+		Find(&standings); res.Error != nil {
+		return nil, res.Error
+	}
+
+	top := make([]PeriodStat, len(standings))
+	for i := range standings {
+		top[i] = PeriodStat{Name: standings[i].User.Name, Scores: standings[i].Scores, Games: standings[i].Games, Wins: standings[i].Wins}
+	}
+	return top, nil
+}
@@ -0,0 +1,203 @@
+/**
+ *
+ * @file stat.go
+ * @brief Database
+ *
+ * Aggregated statistics of players computed by SQL queries
+ */
+
+package db
+
+import (
+	// System
+	"time"
+	// Third-party
+	// Project
+)
+
+/**
+ *
+ * @class VersusStat
+ * @brief Head-to-head statistics of two players.
+ *
+ * Only ranked games are counted, practice games are skipped.
+ * Wins are counted only against each other: game won by the third player
+ * or by team of both players isn't a win of anybody of them.
+ */
+type VersusStat struct {
+	Meetings uint
+	Wins1    uint
+	Wins2    uint
+	Avg1     float64
+	Avg2     float64
+}
+
+/**
+ *
+ * @class Meeting
+ * @brief Finished game of two players.
+ *
+ */
+type Meeting struct {
+	GameID     uint
+	FinishedAt time.Time
+	Score1     uint
+	Score2     uint
+	Winner     string
+	WinnerTeam string
+}
+
+/**
+ *
+ * @brief Joins of finished ranked games, where both players were.
+ * Player 1 is "a", player 2 is "b", game session is "gs", winner is "w".
+ */
+const versusJoins = `FROM user_in_games a
+	JOIN user_in_games b ON b.game_id = a.game_id AND b.deleted_at IS NULL
+	JOIN game_sessions gs ON gs.id = a.game_id
+	LEFT JOIN users w ON w.id = gs.winner_id
+	WHERE a.user_id = ? AND b.user_id = ? AND a.deleted_at IS NULL AND gs.ranked = ? AND gs.finished_at IS NOT NULL`
+
+/**
+ *
+ * @brief Returns head-to-head statistics of two players.
+ * @param[in] username of player 1
+ * @param[in] username of player 2
+ * @param[in] limit of the last meetings
+ * @return statistics of meetings
+ * @return the last meetings from the latest one
+ * @return error
+ *
+ */
+func Versus(username1 string, username2 string, limit uint) (*VersusStat, []Meeting, error) {
+
+	user1 := User{}
+	if res := db.Where("name = ? and ip_addr <> ?", username1, botAddr).First(&user1); res.Error != nil {
+		return nil, nil, res.Error
+	}
+	user2 := User{}
+	if res := db.Where("name = ? and ip_addr <> ?", username2, botAddr).First(&user2); res.Error != nil {
+		return nil, nil, res.Error
+	}
+
+	stat := VersusStat{}
+	if res := db.Raw(`SELECT COUNT(*) AS meetings,
+		COALESCE(SUM(CASE WHEN gs.winner_id = a.user_id OR
+			(gs.winner_team <> '' AND gs.winner_team = a.team AND a.team <> b.team) THEN 1 ELSE 0 END), 0) AS wins1,
+		COALESCE(SUM(CASE WHEN gs.winner_id = b.user_id OR
+			(gs.winner_team <> '' AND gs.winner_team = b.team AND a.team <> b.team) THEN 1 ELSE 0 END), 0) AS wins2,
+		COALESCE(AVG(a.score), 0) AS avg1,
+		COALESCE(AVG(b.score), 0) AS avg2 `+versusJoins,
+		user1.ID, user2.ID, true).Scan(&stat); res.Error != nil {
+		return nil, nil, res.Error
+	}
+
+	meetings := []Meeting{}
+	if res := db.Raw(`SELECT gs.id AS game_id, gs.finished_at, a.score AS score1, b.score AS score2,
+		COALESCE(w.name, '') AS winner, gs.winner_team `+versusJoins+`
+		ORDER BY gs.finished_at DESC LIMIT ?`,
+		user1.ID, user2.ID, true, limit).Scan(&meetings); res.Error != nil {
+		return nil, nil, res.Error
+	}
+	return &stat, meetings, nil
+}
+
+/**
+ *
+ * @class Profile
+ * @brief Personal statistics of player.
+ *
+ * Form contains results of the last ranked games from the latest one: W - win, L - loss, D - draw.
+ * Streak is the number of the last games with the same result as the latest one.
+ */
+type Profile struct {
+	User           User
+	LongestWord    string
+	AvgWordLength  float64
+	FavouriteWord  string
+	FavouriteCount uint
+	Form           string
+	Streak         uint
+}
+
+/**
+ *
+ * @brief Results of finished ranked games of player.
+ * Columns: finished_at, result (W, L, D).
+ */
+const resultsQuery = `SELECT gs.finished_at AS finished_at,
+	CASE WHEN gs.winner_id = uig.user_id OR (gs.winner_team <> '' AND gs.winner_team = uig.team) THEN 'W'
+		WHEN COALESCE(gs.winner_id, 0) = 0 AND COALESCE(gs.winner_team, '') = '' THEN 'D'
+		ELSE 'L' END AS result
+	FROM user_in_games uig
+	JOIN game_sessions gs ON gs.id = uig.game_id
+	WHERE uig.user_id = ? AND uig.deleted_at IS NULL AND gs.ranked = ? AND gs.finished_at IS NOT NULL`
+
+/**
+ *
+ * @brief Returns personal statistics of player.
+ * @param[in] username of player
+ * @param[in] number of the last games in form
+ * @return profile of player
+ * @return error
+ *
+ */
+func UserProfile(username string, formLength uint) (*Profile, error) {
+
+	profile := Profile{}
+	if res := db.Where("name = ? and ip_addr <> ?", username, botAddr).First(&profile.User); res.Error != nil {
+		return nil, res.Error
+	}
+	id := profile.User.ID
+
+	words := []struct {
+		Word  string
+		Count uint
+	}{}
+	if res := db.Raw(`(SELECT rw.word AS word, ul.count AS count
+		FROM users_lexicons ul JOIN rus_words rw ON rw.id = ul.rus_word_id
+		WHERE ul.user_id = ? AND ul.deleted_at IS NULL
+		ORDER BY CHAR_LENGTH(rw.word) DESC, rw.word LIMIT 1)
+		UNION ALL
+		(SELECT rw.word AS word, ul.count AS count
+		FROM users_lexicons ul JOIN rus_words rw ON rw.id = ul.rus_word_id
+		WHERE ul.user_id = ? AND ul.deleted_at IS NULL
+		ORDER BY ul.count DESC, rw.word LIMIT 1)`,
+		id, id).Scan(&words); res.Error != nil {
+		return nil, res.Error
+	}
+	if len(words) == 2 {
+		profile.LongestWord = words[0].Word
+		profile.FavouriteWord = words[1].Word
+		profile.FavouriteCount = words[1].Count
+	}
+
+	length := struct{ AvgWordLength float64 }{}
+	if res := db.Raw(`SELECT COALESCE(SUM(CHAR_LENGTH(rw.word) * ul.count) / SUM(ul.count), 0) AS avg_word_length
+		FROM users_lexicons ul JOIN rus_words rw ON rw.id = ul.rus_word_id
+		WHERE ul.user_id = ? AND ul.deleted_at IS NULL`,
+		id).Scan(&length); res.Error != nil {
+		return nil, res.Error
+	}
+	profile.AvgWordLength = length.AvgWordLength
+
+	form := []struct{ Result string }{}
+	if res := db.Raw(resultsQuery+" ORDER BY gs.finished_at DESC LIMIT ?", id, true, formLength).Scan(&form); res.Error != nil {
+		return nil, res.Error
+	}
+	if len(form) == 0 {
+		return &profile, nil
+	}
+	for i := range form {
+		profile.Form += form[i].Result
+	}
+
+	streak := struct{ Streak uint }{}
+	if res := db.Raw(`SELECT COUNT(*) AS streak FROM (`+resultsQuery+`) t
+		WHERE t.finished_at > COALESCE((SELECT MAX(t2.finished_at) FROM (`+resultsQuery+`) t2 WHERE t2.result <> ?), ?)`,
+		id, true, id, true, form[0].Result, time.Unix(0, 0)).Scan(&streak); res.Error != nil {
+		return nil, res.Error
+	}
+	profile.Streak = streak.Streak
+	return &profile, nil
+}
@@ -0,0 +1,102 @@
+/**
+ * @file stat_test.go
+ * @brief Tests of statistics
+ *
+ * Tests need MySQL database: path to config with it is taken
+ * from BALDA_TEST_CONFIG, tests are skipped if it isn't set.
+ */
+
+package db
+
+import (
+	// System
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	// Third-party
+
+	// Project
+	"github.com/BaldaGo/balda-go/conf"
+)
+
+/**
+ * @brief Connect to test database
+ * @param[in] t Test
+ */
+func testDB(t *testing.T) {
+	file := os.Getenv("BALDA_TEST_CONFIG")
+	if file == "" {
+		t.Skip("BALDA_TEST_CONFIG isn't set")
+	}
+
+	cfg, err := conf.New(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Init(cfg.Database); err != nil {
+		t.Fatal(err)
+	}
+}
+
+/**
+ * @brief Play finished game of two players, the first one wins
+ * @param[in] t Test
+ * @param[in] user1 Name of the winner
+ * @param[in] user2 Name of the loser
+ * @param[in] ranked Flag of ranked game
+ * @return id of the game
+ */
+func testGame(t *testing.T, user1 string, user2 string, ranked bool) uint {
+	game, err := StartGame()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{user1, user2} {
+		if _, err := NewUserInSession(name, game.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := SetupGame(game.ID, "classic", 1, "ru", "classic", "random", "балда", []string{user1, user2}, ranked); err != nil {
+		t.Fatal(err)
+	}
+	result := GameResult{
+		Scores:  map[string]int{user1: 10, user2: 5},
+		Winners: []string{user1},
+	}
+	if err := GameOver(game.ID, result); err != nil {
+		t.Fatal(err)
+	}
+	return game.ID
+}
+
+/**
+ * @brief Practice games aren't counted in head-to-head statistics
+ */
+func TestVersusRankedOnly(t *testing.T) {
+	testDB(t)
+
+	suffix := time.Now().UnixNano() % 1000000
+	user1 := fmt.Sprintf("vs1_%d", suffix)
+	user2 := fmt.Sprintf("vs2_%d", suffix)
+	for _, name := range []string{user1, user2} {
+		if _, err := AddUser(name, "password", "127.0.0.1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ranked := testGame(t, user1, user2, true)
+	testGame(t, user1, user2, false)
+
+	stat, meetings, err := Versus(user1, user2, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stat.Meetings != 1 || stat.Wins1 != 1 || stat.Wins2 != 0 {
+		t.Errorf("Statistics are %+v, want 1 meeting won by %s", *stat, user1)
+	}
+	if len(meetings) != 1 || meetings[0].GameID != ranked {
+		t.Errorf("Meetings are %+v, want only ranked game %d", meetings, ranked)
+	}
+}
@@ -0,0 +1,316 @@
+/**
+ *
+ * @file tournament.go
+ * @brief Database
+ *
+ * Tournaments, their players and games, so tournaments survive restarts of server
+ */
+
+package db
+
+import (
+	// System
+
+	// Third-party
+	"github.com/jinzhu/gorm"
+	// Project
+)
+
+/**
+ *
+ * @class Tournament
+ * @brief The table contains tournaments.
+ *
+ * Status is one of: registration, running, finished.
+ * Round is the number of the current round, 0 before tournament starts.
+ */
+type Tournament struct {
+	gorm.Model
+
+	Name     string `gorm:"type:VARCHAR(100) CHARACTER SET utf8 COLLATE utf8_general_ci"`
+	Format   string `gorm:"type:VARCHAR(16)"`
+	Language string `gorm:"type:VARCHAR(16)"`
+	Ruleset  string `gorm:"type:VARCHAR(32)"`
+	Status   string `gorm:"type:VARCHAR(16);index"`
+	Rounds   uint   `gorm:"default:0"`
+	Round    uint   `gorm:"default:0"`
+}
+
+/**
+ *
+ * @class TournamentPlayer
+ * @brief The table contains players of tournaments and their points.
+ *
+ * Win gives 1 point, draw gives half of point. Scores are used to break ties.
+ */
+type TournamentPlayer struct {
+	gorm.Model
+
+	TournamentID uint `gorm:"index"`
+	UserID       uint
+	Points       float64 `gorm:"default:0"`
+	Scores       uint    `gorm:"default:0"`
+	Byes         uint    `gorm:"default:0"`
+	User         User    `gorm:"ForeignKey:UserID"`
+}
+
+/**
+ *
+ * @class TournamentGame
+ * @brief The table contains pairings of tournament rounds.
+ *
+ * Player2ID is 0 if Player1 has a bye. GameID is the id of game session,
+ * where players play, it is 0 until free session is found for them.
+ * WinnerID is 0 if it is a draw. Forfeit is set if game isn't played to the end,
+ * WinnerID is 0 then if both players lose.
+ */
+type TournamentGame struct {
+	gorm.Model
+
+	TournamentID uint `gorm:"index"`
+	Round        uint
+	Player1ID    uint
+	Player2ID    uint
+	Session      int
+	GameID       uint `gorm:"index"`
+	Finished     bool `gorm:"default:false"`
+	Forfeit      bool `gorm:"default:false"`
+	WinnerID     uint
+	Tournament   Tournament `gorm:"ForeignKey:TournamentID"`
+	Player1      User       `gorm:"ForeignKey:Player1ID"`
+	Player2      User       `gorm:"ForeignKey:Player2ID"`
+}
+
+/**
+ *
+ * @brief Creates tournament open for registration.
+ * @param[in] name of tournament
+ * @param[in] format of tournament (roundrobin, swiss)
+ * @param[in] language of games
+ * @param[in] ruleset of games
+ * @param[in] number of rounds (0 to choose it when tournament starts)
+ * @return the record just created for the new tournament.
+ * @return error
+ *
+ */
+func CreateTournament(name string, format string, language string, ruleset string, rounds uint) (*Tournament, error) {
+
+	tournament := Tournament{Name: name, Format: format, Language: language, Ruleset: ruleset, Status: "registration", Rounds: rounds}
+	if res := db.Create(&tournament); res.Error != nil {
+		return nil, res.Error
+	}
+	return &tournament, nil
+}
+
+/**
+ *
+ * @brief Returns tournaments, which aren't finished.
+ * @return tournaments from the oldest one
+ * @return error
+ *
+ */
+func ActiveTournaments() ([]Tournament, error) {
+
+	tournaments := []Tournament{}
+	if res := db.Where("status <> ?", "finished").Order("id").Find(&tournaments); res.Error != nil {
+		return nil, res.Error
+	}
+	return tournaments, nil
+}
+
+/**
+ *
+ * @brief Returns tournament with its players and games.
+ * @param[in] id of tournament
+ * @return tournament record
+ * @return players in order of registration
+ * @return games from the first round
+ * @return error
+ *
+ */
+func TournamentByID(id uint) (*Tournament, []TournamentPlayer, []TournamentGame, error) {
+
+	tournament := Tournament{}
+	if res := db.Where("id = ?", id).First(&tournament); res.Error != nil {
+		return nil, nil, nil, res.Error
+	}
+
+	players := []TournamentPlayer{}
+	if res := db.
+		Where("tournament_id = ?", id).
+		Order("id").
+		Preload("User").
+		Find(&players); res.Error != nil {
+		return nil, nil, nil, res.Error
+	}
+
+	games := []TournamentGame{}
+	if res := db.
+		Where("tournament_id = ?", id).
+		Order("round, id").
+		Preload("Player1").
+		Preload("Player2").
+		Find(&games); res.Error != nil {
+		return nil, nil, nil, res.Error
+	}
+	return &tournament, players, games, nil
+}
+
+/**
+ *
+ * @brief Registers user in tournament.
+ * @param[in] id of tournament
+ * @param[in] username of user
+ * @return the record just created for the new player.
+ * @return error
+ *
+ */
+func AddTournamentPlayer(id uint, username string) (*TournamentPlayer, error) {
+
+	user := User{}
+	if res := db.Where("name = ?", username).First(&user); res.Error != nil {
+		return nil, res.Error
+	}
+
+	player := TournamentPlayer{TournamentID: id, UserID: user.ID}
+	if res := db.Create(&player); res.Error != nil {
+		return nil, res.Error
+	}
+	player.User = user
+	return &player, nil
+}
+
+/**
+ *
+ * @brief Saves tournament, its players and games.
+ * @param[in] tournament record
+ * @param[in] players with their points
+ * @param[in] games, new games are created
+ * @return error
+ *
+ * All changes are made in one transaction.
+ */
+func SaveTournament(tournament *Tournament, players []TournamentPlayer, games []TournamentGame) error {
+
+	tx := db.Set("gorm:save_associations", false).Begin()
+	if res := tx.Save(tournament); res.Error != nil {
+		tx.Rollback()
+		return res.Error
+	}
+	for i := range players {
+		if res := tx.Save(&players[i]); res.Error != nil {
+			tx.Rollback()
+			return res.Error
+		}
+	}
+	for i := range games {
+		if res := tx.Save(&games[i]); res.Error != nil {
+			tx.Rollback()
+			return res.Error
+		}
+	}
+	return tx.Commit().Error
+}
+
+/**
+ *
+ * @brief Saves tournament game.
+ * @param[in] game record
+ * @return error
+ *
+ */
+func SaveTournamentGame(game *TournamentGame) error {
+
+	if res := db.Set("gorm:save_associations", false).Save(game); res.Error != nil {
+		return res.Error
+	}
+	return nil
+}
+
+/**
+ *
+ * @brief Returns tournament game played in game session.
+ * @param[in] game session id
+ * @return game record (nil if it isn't tournament game)
+ * @return error
+ *
+ */
+func TournamentGameByGameID(gameID uint) (*TournamentGame, error) {
+
+	game := TournamentGame{}
+	res := db.Where("game_id = ?", gameID).First(&game)
+	if res.RecordNotFound() {
+		return nil, nil
+	}
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	return &game, nil
+}
+
+/**
+ *
+ * @brief Returns games of running tournaments, which wait for free session.
+ * @return games with their tournaments and players
+ * @return error
+ *
+ */
+func PendingTournamentGames() ([]TournamentGame, error) {
+
+	games := []TournamentGame{}
+	if res := db.
+		Joins("JOIN tournaments ON tournaments.id = tournament_games.tournament_id").
+		Where("tournaments.status = ? and tournament_games.finished = ? and tournament_games.game_id = 0", "running", false).
+		Order("tournament_games.id").
+		Preload("Tournament").
+		Preload("Player1").
+		Preload("Player2").
+		Find(&games); res.Error != nil {
+		return nil, res.Error
+	}
+	return games, nil
+}
+
+/**
+ *
+ * @brief Frees sessions of all unfinished tournament games.
+ * @return error
+ *
+ * Sessions are lost when server restarts, so games wait for new sessions.
+ */
+func UnassignTournamentGames() error {
+
+	if res := db.
+		Model(&TournamentGame{}).
+		Where("finished = ? and game_id <> 0", false).
+		Updates(map[string]interface{}{"game_id": 0, "session": 0}); res.Error != nil {
+		return res.Error
+	}
+	return nil
+}
+
+/**
+ *
+ * @brief Returns unfinished tournament games of user, which have sessions.
+ * @param[in] username of user
+ * @return games with their tournaments
+ * @return error
+ *
+ */
+func UserTournamentGames(username string) ([]TournamentGame, error) {
+
+	user := User{}
+	if res := db.Where("name = ?", username).First(&user); res.Error != nil {
+		return nil, res.Error
+	}
+
+	games := []TournamentGame{}
+	if res := db.
+		Where("finished = ? and game_id <> 0 and (player1_id = ? or player2_id = ?)", false, user.ID, user.ID).
+		Order("id").
+		Preload("Tournament").
+		Find(&games); res.Error != nil {
+		return nil, res.Error
+	}
+	return games, nil
+}
@@ -0,0 +1,374 @@
+/**
+ * @file automaton.go
+ * @brief Minimized automaton of dictionary keys
+ *
+ * Stores all keys of the dictionary in a minimized acyclic automaton (DAWG)
+ * with byte transitions. The automaton is kept as a flat table of edges,
+ * so the same bytes are used in memory and in compiled dictionary file.
+ *
+ * Edge format (6 bytes): label (1), flags (1), index of first edge of target node (4, big endian)
+ */
+
+package dict
+
+import (
+	// System
+	"encoding/binary"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	// Third-party
+	// Project
+)
+
+const (
+	edgeSize  = 6          ///< Size of one edge in bytes
+	edgeLast  = 1 << 0     ///< Flag, edge is the last edge of its node
+	edgeFinal = 1 << 1     ///< Flag, target node is final (key ends here)
+	noEdges   = 0xFFFFFFFF ///< Target index of node without edges
+)
+
+/**
+ * @class Automaton
+ * @brief Class, provides minimized automaton over table of edges
+ *
+ * Node is represented by index of its first edge, root is node 0
+ */
+type Automaton struct {
+	edges []byte ///< Table of edges
+}
+
+/**
+ * @brief Create automaton over table of edges
+ * @param[in] edges Table of edges (from compiled dictionary file)
+ * @return a Pointer to new Automaton or error if table is malformed
+ */
+func NewAutomaton(edges []byte) (*Automaton, error) {
+	if len(edges) == 0 || len(edges)%edgeSize != 0 {
+		return nil, errors.New("Malformed automaton: wrong size of edges table")
+	}
+	return &Automaton{edges: edges}, nil
+}
+
+/**
+ * @brief Table of edges to save into file
+ */
+func (a *Automaton) Bytes() []byte {
+	return a.edges
+}
+
+/**
+ * @brief Find transition from node by label
+ * @param[in] node Index of first edge of node
+ * @param[in] label Byte of key
+ * @return target Index of target node
+ * @return final Flag if target node is final
+ * @return ok Flag if transition exists
+ */
+func (a *Automaton) next(node uint32, label byte) (uint32, bool, bool) {
+	if node == noEdges {
+		return 0, false, false
+	}
+	for i := int(node) * edgeSize; i+edgeSize <= len(a.edges); i += edgeSize {
+		if a.edges[i] == label {
+			return binary.BigEndian.Uint32(a.edges[i+2:]), a.edges[i+1]&edgeFinal != 0, true
+		}
+		if a.edges[i+1]&edgeLast != 0 {
+			break
+		}
+	}
+	return 0, false, false
+}
+
+/**
+ * @brief Walk automaton by key
+ * @param[in] key Bytes to walk
+ * @return node Node where walk stopped
+ * @return final Flag if key is accepted
+ * @return ok Flag if all key was walked
+ */
+func (a *Automaton) walk(key string) (uint32, bool, bool) {
+	var node uint32
+	final := false
+	for i := 0; i < len(key); i++ {
+		var ok bool
+		if node, final, ok = a.next(node, key[i]); !ok {
+			return 0, false, false
+		}
+	}
+	return node, final, true
+}
+
+/**
+ * @brief Predicate, check if automaton accepts key
+ * @param[in] key Checking key
+ */
+func (a *Automaton) Contains(key string) bool {
+	_, final, ok := a.walk(key)
+	return ok && final
+}
+
+/**
+ * @brief Predicate, check if some key starts with prefix
+ * @param[in] prefix Checking prefix
+ */
+func (a *Automaton) HasPrefix(prefix string) bool {
+	_, _, ok := a.walk(prefix)
+	return ok
+}
+
+/**
+ * @brief Predicate, check if some key continues prefix by label other than given one
+ * @param[in] prefix Checking prefix
+ * @param[in] except Label, which is not considered as continuation
+ */
+func (a *Automaton) continues(prefix string, except byte) bool {
+	node, _, ok := a.walk(prefix)
+	if !ok || node == noEdges {
+		return false
+	}
+	for i := int(node) * edgeSize; i+edgeSize <= len(a.edges); i += edgeSize {
+		if a.edges[i] != except {
+			return true
+		}
+		if a.edges[i+1]&edgeLast != 0 {
+			break
+		}
+	}
+	return false
+}
+
+/**
+ * @brief Predicate, check if some key with given prefix satisfies function
+ * @param[in] prefix Prefix of keys
+ * @param[in] f Predicate, gets key without prefix
+ * @return ok True if f returned true, keys after it aren't visited
+ */
+func (a *Automaton) Any(prefix string, f func(suffix string) bool) bool {
+	found := false
+	a.each(prefix, func(suffix string) bool {
+		found = f(suffix)
+		return !found
+	})
+	return found
+}
+
+/**
+ * @brief Call function for every key with given prefix
+ * @param[in] prefix Prefix of keys
+ * @param[in] f Callback, gets key without prefix
+ */
+func (a *Automaton) Each(prefix string, f func(suffix string)) {
+	a.each(prefix, func(suffix string) bool {
+		f(suffix)
+		return true
+	})
+}
+
+/**
+ * @brief Call function for keys with given prefix while it returns true
+ * @param[in] prefix Prefix of keys
+ * @param[in] f Callback, gets key without prefix
+ */
+func (a *Automaton) each(prefix string, f func(suffix string) bool) {
+	node, final, ok := a.walk(prefix)
+	if !ok {
+		return
+	}
+	if final && !f("") {
+		return
+	}
+
+	var buf []byte
+	var rec func(node uint32) bool
+	rec = func(node uint32) bool {
+		if node == noEdges {
+			return true
+		}
+		for i := int(node) * edgeSize; i+edgeSize <= len(a.edges); i += edgeSize {
+			buf = append(buf, a.edges[i])
+			if a.edges[i+1]&edgeFinal != 0 && !f(string(buf)) {
+				return false
+			}
+			if !rec(binary.BigEndian.Uint32(a.edges[i+2:])) {
+				return false
+			}
+			buf = buf[:len(buf)-1]
+			if a.edges[i+1]&edgeLast != 0 {
+				break
+			}
+		}
+		return true
+	}
+	rec(node)
+}
+
+/**
+ * @class builderNode
+ * @brief Node of automaton while building
+ */
+type builderNode struct {
+	labels  []byte         ///< Labels of edges
+	targets []*builderNode ///< Targets of edges
+	final   bool           ///< Flag if key ends here
+	id      int            ///< Number of registered node, -1 if not registered
+	index   uint32         ///< Index of first edge in table
+}
+
+/**
+ * @class AutomatonBuilder
+ * @brief Class, builds minimized automaton from sorted keys
+ *
+ * Incremental construction by Daciuk, Mihov, Watson and Watson
+ */
+type AutomatonBuilder struct {
+	root     *builderNode            ///< Root node
+	path     []*builderNode          ///< Nodes of the last added key, not minimized yet
+	previous string                  ///< Last added key
+	register map[string]*builderNode ///< Minimized nodes by their signature
+}
+
+/**
+ * @brief Create new AutomatonBuilder
+ */
+func NewAutomatonBuilder() *AutomatonBuilder {
+	root := &builderNode{id: -1}
+	return &AutomatonBuilder{
+		root:     root,
+		path:     []*builderNode{root},
+		register: make(map[string]*builderNode),
+	}
+}
+
+/**
+ * @brief Add key to automaton
+ * @param[in] key Not empty key, it must not be less than previous one
+ * @return err Error if keys are not sorted
+ */
+func (b *AutomatonBuilder) Add(key string) error {
+	if key == b.previous {
+		return nil
+	}
+	if key < b.previous {
+		return errors.New("Keys of automaton must be sorted")
+	}
+
+	common := 0
+	for common < len(key) && common < len(b.previous) && key[common] == b.previous[common] {
+		common++
+	}
+	b.minimize(common)
+
+	node := b.path[len(b.path)-1]
+	for i := common; i < len(key); i++ {
+		child := &builderNode{id: -1}
+		node.labels = append(node.labels, key[i])
+		node.targets = append(node.targets, child)
+		b.path = append(b.path, child)
+		node = child
+	}
+	node.final = true
+	b.previous = key
+
+	return nil
+}
+
+/**
+ * @brief Replace nodes of last key deeper than depth by registered equivalents
+ * @param[in] depth Length of common prefix with the next key
+ */
+func (b *AutomatonBuilder) minimize(depth int) {
+	for len(b.path)-1 > depth {
+		child := b.path[len(b.path)-1]
+		parent := b.path[len(b.path)-2]
+		b.path = b.path[:len(b.path)-1]
+
+		sign := child.signature()
+		if same, ok := b.register[sign]; ok {
+			parent.targets[len(parent.targets)-1] = same
+		} else {
+			child.id = len(b.register)
+			b.register[sign] = child
+		}
+	}
+}
+
+/**
+ * @brief Signature of node, equivalent nodes have the same signature
+ */
+func (n *builderNode) signature() string {
+	parts := make([]string, 0, len(n.labels)+1)
+	parts = append(parts, strconv.FormatBool(n.final))
+	for i := range n.labels {
+		parts = append(parts, strconv.Itoa(int(n.labels[i]))+":"+strconv.Itoa(n.targets[i].id))
+	}
+	return strings.Join(parts, ",")
+}
+
+/**
+ * @brief Finish building and create automaton
+ * @return a Pointer to new Automaton
+ */
+func (b *AutomatonBuilder) Automaton() *Automaton {
+	b.minimize(0)
+
+	var order []*builderNode
+	visited := make(map[*builderNode]bool)
+	var rec func(n *builderNode)
+	rec = func(n *builderNode) {
+		if visited[n] {
+			return
+		}
+		visited[n] = true
+		order = append(order, n)
+		for _, t := range n.targets {
+			rec(t)
+		}
+	}
+	rec(b.root)
+
+	var count uint32
+	for _, n := range order {
+		if len(n.labels) == 0 {
+			n.index = noEdges
+			continue
+		}
+		n.index = count
+		count += uint32(len(n.labels))
+	}
+
+	edges := make([]byte, 0, int(count)*edgeSize)
+	for _, n := range order {
+		for i := range n.labels {
+			var flags byte
+			if i == len(n.labels)-1 {
+				flags |= edgeLast
+			}
+			if n.targets[i].final {
+				flags |= edgeFinal
+			}
+			edges = append(edges, n.labels[i], flags, 0, 0, 0, 0)
+			binary.BigEndian.PutUint32(edges[len(edges)-4:], n.targets[i].index)
+		}
+	}
+	if len(edges) == 0 {
+		edges = append(edges, 0, edgeLast, 0xFF, 0xFF, 0xFF, 0xFF)
+	}
+
+	return &Automaton{edges: edges}
+}
+
+/**
+ * @brief Build automaton from unsorted keys
+ * @param[in] keys Keys of automaton
+ * @return a Pointer to new Automaton
+ */
+func BuildAutomaton(keys []string) *Automaton {
+	sort.Strings(keys)
+	b := NewAutomatonBuilder()
+	for _, key := range keys {
+		b.Add(key)
+	}
+	return b.Automaton()
+}
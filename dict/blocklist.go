@@ -0,0 +1,86 @@
+/**
+ * @file blocklist.go
+ * @brief Offensive words
+ *
+ * Blocklist is loaded from text file next to the dictionary.
+ * Words from it are valid in game, but they are never shown to players
+ * by the server itself (e.g. in puzzles).
+ * Format: one word per line, "stem*" blocks all words which start with stem,
+ * lines starting with '#' are comments
+ */
+
+package dict
+
+import (
+	// System
+	"bufio"
+	"os"
+	"strings"
+	// Third-party
+	// Project
+)
+
+/**
+ * @class blocklist
+ * @brief Class, provides offensive words and stems
+ */
+type blocklist struct {
+	words map[string]bool ///< Blocked words
+	stems []string        ///< Blocked beginnings of words
+}
+
+/**
+ * @brief Read blocklist file
+ * @param[in] path Path to blocklist file
+ * @return err Error if it occured
+ */
+func (d *Dictionary) loadBlocklist(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	b := &blocklist{words: make(map[string]bool)}
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		word := d.foldWord(strings.ToLower(strings.TrimSuffix(line, "*")))
+		if strings.HasSuffix(line, "*") {
+			b.stems = append(b.stems, word)
+		} else {
+			b.words[word] = true
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	d.blocked = b
+	return nil
+}
+
+/**
+ * @brief Predicate, check if word is offensive
+ * @param[in] word Normalized word
+ * @return ok If ok is true, then word is in blocklist or starts with blocked stem
+ */
+func (d *Dictionary) Offensive(word string) bool {
+	if d.blocked == nil {
+		return false
+	}
+	if d.blocked.words[word] {
+		return true
+	}
+	for _, stem := range d.blocked.stems {
+		if strings.HasPrefix(word, stem) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,212 @@
+/**
+ * @file compiled.go
+ * @brief Compiled dictionary
+ *
+ * Binary dictionary file, which is loaded without parsing of words:
+ * 	magic "BALDADIC" (8 bytes), version (4 bytes),
+ * 	length of metadata (4 bytes), metadata in json,
+ * 	length of automaton (4 bytes), table of edges of automaton (see automaton.go),
+ * 	CRC32 checksum of all previous bytes (4 bytes)
+ * All numbers are big endian
+ */
+
+package dict
+
+import (
+	// System
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io/ioutil"
+	"os"
+	"reflect"
+
+	// Third-party
+
+	// Project
+	"github.com/BaldaGo/balda-go/conf"
+)
+
+const (
+	compiledMagic   = "BALDADIC" ///< First bytes of compiled dictionary file
+	compiledVersion = 1          ///< Version of compiled dictionary format
+)
+
+/**
+ * @class metadata
+ * @brief Class, provides information about compiled dictionary
+ */
+type metadata struct {
+	Name       string            ///< Name of dictionary (language)
+	Alphabet   string            ///< Letters of the language
+	Fold       map[string]string ///< Letters replaced while normalizing
+	Tagged     bool              ///< Flag if words have grammatical tags
+	Words      int               ///< Number of words
+	SourceSize int64             ///< Size of text dictionary
+	SourceCRC  uint32            ///< CRC32 checksum of text dictionary
+}
+
+/**
+ * @brief Compile text dictionary into binary file
+ * @param[in] cfg Dictionary configuration
+ * @param[in] output Path to compiled dictionary (cfg.Compiled if empty)
+ * @return err Error if it occured
+ */
+func Compile(cfg conf.DictConf, output string) error {
+	if output == "" {
+		output = cfg.Compiled
+	}
+	if output == "" {
+		return errors.New(fmt.Sprintf("Output path of compiled dictionary '%s' is not given", cfg.Name))
+	}
+
+	size, crc, err := sourceChecksum(cfg.Path)
+	if err != nil {
+		return err
+	}
+
+	d, err := loadText(cfg)
+	if err != nil {
+		return err
+	}
+
+	meta, err := json.Marshal(metadata{
+		Name:       d.Name,
+		Alphabet:   string(d.alphabet),
+		Fold:       cfg.Fold,
+		Tagged:     d.tagged,
+		Words:      d.size,
+		SourceSize: size,
+		SourceCRC:  crc,
+	})
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(compiledMagic)
+	binary.Write(&buf, binary.BigEndian, uint32(compiledVersion))
+	binary.Write(&buf, binary.BigEndian, uint32(len(meta)))
+	buf.Write(meta)
+	binary.Write(&buf, binary.BigEndian, uint32(len(d.auto.Bytes())))
+	buf.Write(d.auto.Bytes())
+	binary.Write(&buf, binary.BigEndian, crc32.ChecksumIEEE(buf.Bytes()))
+
+	return ioutil.WriteFile(output, buf.Bytes(), 0644)
+}
+
+/**
+ * @brief Load compiled dictionary
+ * @param[in] cfg Dictionary configuration
+ * @return d Pointer to the loaded Dictionary or error if file is corrupted or stale
+ *
+ * File is stale if text dictionary or alphabet changed after compilation
+ */
+func LoadCompiled(cfg conf.DictConf) (*Dictionary, error) {
+	data, err := ioutil.ReadFile(cfg.Compiled)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < len(compiledMagic)+16 || string(data[:len(compiledMagic)]) != compiledMagic {
+		return nil, errors.New("Not a compiled dictionary")
+	}
+
+	body := data[:len(data)-4]
+	if crc32.ChecksumIEEE(body) != binary.BigEndian.Uint32(data[len(data)-4:]) {
+		return nil, errors.New("Checksum mismatch, file is corrupted")
+	}
+
+	pos := len(compiledMagic)
+	if version := binary.BigEndian.Uint32(body[pos:]); version != compiledVersion {
+		return nil, errors.New(fmt.Sprintf("Unsupported version %d", version))
+	}
+	pos += 4
+
+	var meta metadata
+	metaLen := int(binary.BigEndian.Uint32(body[pos:]))
+	pos += 4
+	if pos+metaLen+4 > len(body) || json.Unmarshal(body[pos:pos+metaLen], &meta) != nil {
+		return nil, errors.New("Malformed metadata")
+	}
+	pos += metaLen
+
+	autoLen := int(binary.BigEndian.Uint32(body[pos:]))
+	pos += 4
+	if pos+autoLen != len(body) {
+		return nil, errors.New("Malformed automaton")
+	}
+
+	d, err := newDictionary(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = d.checkFresh(cfg, meta); err != nil {
+		return nil, err
+	}
+
+	if d.auto, err = NewAutomaton(body[pos:]); err != nil {
+		return nil, err
+	}
+
+	for _, r := range meta.Alphabet {
+		d.letters[r] = true
+	}
+	d.sortAlphabet()
+	d.tagged = meta.Tagged
+	d.size = meta.Words
+
+	return d, nil
+}
+
+/**
+ * @brief Check that compiled dictionary matches configuration and text dictionary
+ * @param[in] cfg Dictionary configuration
+ * @param[in] meta Metadata of compiled dictionary
+ * @return err Error if compiled dictionary is stale
+ */
+func (d *Dictionary) checkFresh(cfg conf.DictConf, meta metadata) error {
+	if meta.Name != cfg.Name {
+		return errors.New(fmt.Sprintf("It is compiled for dictionary '%s'", meta.Name))
+	}
+
+	if len(meta.Fold) != 0 || len(cfg.Fold) != 0 {
+		if !reflect.DeepEqual(meta.Fold, cfg.Fold) {
+			return errors.New("Stale file, folding changed")
+		}
+	}
+
+	if cfg.Alphabet != "" && string(d.alphabet) != meta.Alphabet {
+		return errors.New("Stale file, alphabet changed")
+	}
+
+	if _, err := os.Stat(cfg.Path); os.IsNotExist(err) {
+		return nil
+	}
+
+	size, crc, err := sourceChecksum(cfg.Path)
+	if err != nil {
+		return err
+	}
+	if size != meta.SourceSize || crc != meta.SourceCRC {
+		return errors.New(fmt.Sprintf("Stale file, %s changed", cfg.Path))
+	}
+
+	return nil
+}
+
+/**
+ * @brief Size and CRC32 checksum of text dictionary
+ * @param[in] path Path to text dictionary
+ */
+func sourceChecksum(path string) (int64, uint32, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	return int64(len(data)), crc32.ChecksumIEEE(data), nil
+}
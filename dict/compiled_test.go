@@ -0,0 +1,140 @@
+/**
+ * @file compiled_test.go
+ * @brief Tests of compiled dictionary
+ */
+
+package dict
+
+import (
+	// System
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	// Third-party
+
+	// Project
+	"github.com/BaldaGo/balda-go/conf"
+)
+
+/**
+ * @brief Write text dictionary into temporary directory
+ * @param[in] t Test
+ * @param[in] text Content of dictionary file
+ * @return cfg Dictionary configuration with paths in temporary directory
+ * @return cleanup Function removing temporary directory
+ */
+func testDict(t *testing.T, text string) (conf.DictConf, func()) {
+	dir, err := ioutil.TempDir("", "balda-dict")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := conf.DictConf{
+		Name:     "ru",
+		Path:     filepath.Join(dir, "dictionary.txt"),
+		Compiled: filepath.Join(dir, "dictionary.bin"),
+		Fold:     map[string]string{"ё": "е"},
+	}
+	if err := ioutil.WriteFile(cfg.Path, []byte(text), 0644); err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	return cfg, func() { os.RemoveAll(dir) }
+}
+
+/**
+ * @brief Compiled dictionary has the same words and prefixes as text one
+ */
+func TestCompileRoundTrip(t *testing.T) {
+	cfg, cleanup := testDict(t, "# comment\nабажур\tабажур\tNOUN,inan,masc,sing,nomn\nабажуры\tабажур\tNOUN,inan,masc,plur,nomn\nёлка\nбалда\n")
+	defer cleanup()
+
+	if err := Compile(cfg, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := LoadCompiled(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text, err := loadText(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d.Size() != 4 || d.Size() != text.Size() {
+		t.Errorf("Compiled dictionary has %d words, text one has %d", d.Size(), text.Size())
+	}
+	if string(d.Alphabet()) != string(text.Alphabet()) {
+		t.Errorf("Alphabet %q differs from %q", string(d.Alphabet()), string(text.Alphabet()))
+	}
+	if !d.tagged {
+		t.Error("Tags are lost")
+	}
+
+	for _, word := range []string{"абажур", "абажуры", "елка", "балда", "абаж", "бал", "ел", "абажуры\x00", "балды", "ёлка", "абв", ""} {
+		if d.CheckWord(word) != text.CheckWord(word) {
+			t.Errorf("CheckWord(%q) = %v, text dictionary gives %v", word, d.CheckWord(word), text.CheckWord(word))
+		}
+		if d.CheckPrefix(word) != text.CheckPrefix(word) {
+			t.Errorf("CheckPrefix(%q) = %v, text dictionary gives %v", word, d.CheckPrefix(word), text.CheckPrefix(word))
+		}
+	}
+
+	if !d.CheckWord("елка") || d.CheckWord("абаж") || !d.CheckPrefix("абаж") || d.CheckPrefix("абв") {
+		t.Error("Compiled dictionary gives wrong answers")
+	}
+
+	if entries := d.Entries("абажуры"); len(entries) != 1 || entries[0].Lemma != "абажур" || !entries[0].Has("plur") {
+		t.Errorf("Entries of 'абажуры' are %v", entries)
+	}
+}
+
+/**
+ * @brief Corrupted or stale compiled dictionary is not loaded
+ */
+func TestCompileCorrupted(t *testing.T) {
+	cfg, cleanup := testDict(t, "абажур\nбалда\n")
+	defer cleanup()
+
+	if err := Compile(cfg, ""); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(cfg.Compiled)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	corrupted := append([]byte{}, data...)
+	corrupted[len(corrupted)/2] ^= 0xff
+	if err := ioutil.WriteFile(cfg.Compiled, corrupted, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadCompiled(cfg); err == nil {
+		t.Error("Corrupted dictionary is loaded")
+	}
+
+	if err := ioutil.WriteFile(cfg.Compiled, data[:len(data)-10], 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadCompiled(cfg); err == nil {
+		t.Error("Truncated dictionary is loaded")
+	}
+
+	if err := ioutil.WriteFile(cfg.Compiled, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(cfg.Path, []byte("абажур\nбалда\nбалды\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadCompiled(cfg); err == nil {
+		t.Error("Stale dictionary is loaded")
+	}
+
+	cfg.Name = "en"
+	if _, err := LoadCompiled(cfg); err == nil {
+		t.Error("Dictionary of other language is loaded")
+	}
+}
@@ -0,0 +1,85 @@
+/**
+ * @file definitions.go
+ * @brief Definitions of words
+ *
+ * Short definitions are loaded from text file next to the dictionary.
+ * Format: "word<TAB>definition", lines starting with '#' are comments.
+ * If word is listed several times, its definitions are joined
+ */
+
+package dict
+
+import (
+	// System
+	"bufio"
+	"os"
+	"strings"
+	// Third-party
+	// Project
+)
+
+/**
+ * @brief Read definitions file
+ * @param[in] path Path to definitions file
+ * @return err Error if it occured
+ */
+func (d *Dictionary) loadDefinitions(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	d.definitions = make(map[string]string)
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.SplitN(line, "\t", 2)
+		if len(fields) != 2 {
+			continue
+		}
+
+		word := d.foldWord(strings.ToLower(strings.TrimSpace(fields[0])))
+		text := strings.TrimSpace(fields[1])
+		if prev, ok := d.definitions[word]; ok {
+			text = prev + "; " + text
+		}
+		d.definitions[word] = text
+	}
+
+	return scanner.Err()
+}
+
+/**
+ * @brief Find definition of the word
+ * @param[in] word Normalized word
+ * @return lemma Word which definition is found (word itself or its initial form)
+ * @return text Definition
+ * @return ok Flag if definition is found
+ */
+func (d *Dictionary) Define(word string) (string, string, bool) {
+	if text, ok := d.definitions[word]; ok {
+		return word, text, true
+	}
+
+	for _, e := range d.Entries(word) {
+		if text, ok := d.definitions[e.Lemma]; ok {
+			return e.Lemma, text, true
+		}
+	}
+	return "", "", false
+}
+
+/**
+ * @brief Predicate, check if the word has definition
+ * @param[in] word Normalized word
+ */
+func (d *Dictionary) HasDefinition(word string) bool {
+	_, _, ok := d.Define(word)
+	return ok
+}
@@ -2,7 +2,8 @@
  * @file dict.go
  * @brief Dictionary of allowed words
  *
- * Stores the dictionary of valid words in the game
+ * Stores named dictionaries of valid words in the game,
+ * one dictionary for every supported language
  */
 
 package dict
@@ -10,66 +11,515 @@ package dict
 import (
 	//System
 	"bufio"
+	"errors"
+	"fmt"
 	"math/rand"
 	"os"
+	"sort"
+	"strings"
+	"sync"
 	"time"
+	"unicode"
 	"unicode/utf8"
+
 	// Third-party
+
 	// Project
+	"github.com/BaldaGo/balda-go/conf"
+	"github.com/BaldaGo/balda-go/logger"
 )
 
-var wordMap map[string]bool // Map of words from dictionary
-var wordsOfAS []string      // Slice of words with AreaSize length
+/**
+ * @class Dictionary
+ * @brief Class, provides words of one language
+ *
+ * Words are stored in minimized automaton with keys "word\x00lemma\x00tags"
+ * ("word\x00" for words without tags)
+ */
+type Dictionary struct {
+	Name     string                 ///< Name of dictionary (language)
+	Ruleset  string                 ///< Name of ruleset which filtered this dictionary (empty for full one)
+	auto     *Automaton             ///< Automaton of words with their grammatical forms
+	filter   Filter                 ///< Policy of ruleset which words are allowed
+	overlay  *Overlay               ///< Words changed at runtime
+	tagged   bool                   ///< Flag if words have grammatical tags
+	size     int                    ///< Number of words in full dictionary
+	alphabet []rune                 ///< Sorted letters of the language
+	letters  map[rune]bool          ///< Set of letters of the language
+	fold     map[rune]rune          ///< Letters replaced while normalizing (e.g. ё -> е)
+	bySize   map[int][]string       ///< Words grouped by their length (filled on demand)
+	mu       sync.Mutex             ///< Mutex for bySize
+	rulesets map[string]*Dictionary ///< Filtered copies of dictionary by ruleset name
+
+	definitions map[string]string ///< Short definitions by word
+	startWords  []string          ///< Curated start words
+	blocked     *blocklist        ///< Offensive words, which aren't shown to players
+}
+
+const sep = "\x00" ///< Separator of word, lemma and tags in keys of automaton
+
+var dicts = make(map[string]*Dictionary) // Map of loaded dictionaries by name
 
 /**
- * @brief Initialization of wordMap, wordsOfAS and rand
- * @param[in] as Length side of the playing area
- * @param[in] path Relative path to the dictionary
- * @return err Error if it occured
+ * @brief Load dictionary and register it by name
+ * @param[in] cfg Dictionary configuration
+ * @param[in] rulesets Rulesets to filter dictionary
+ * @return d Pointer to the loaded Dictionary or error if it occured
+ */
+func Load(cfg conf.DictConf, rulesets []conf.RulesetConf) (*Dictionary, error) {
+	d, err := load(cfg, rulesets)
+	if err != nil {
+		return nil, err
+	}
+
+	swap(d)
+	return d, nil
+}
+
+/**
+ * @brief Load dictionary without registering
+ * @param[in] cfg Dictionary configuration
+ * @param[in] rulesets Rulesets to filter dictionary
+ * @return d Pointer to the loaded Dictionary or error if it occured
+ *
+ * Loads compiled dictionary if it is configured, fresh and not corrupted,
+ * otherwise reads text dictionary
+ */
+func load(cfg conf.DictConf, rulesets []conf.RulesetConf) (*Dictionary, error) {
+	var d *Dictionary
+	var err error
+
+	if cfg.Compiled != "" {
+		start := time.Now()
+		if d, err = LoadCompiled(cfg); err != nil {
+			logger.Log.Warningf("Compiled dictionary %s is not used (%s), reading %s", cfg.Compiled, err.Error(), cfg.Path)
+			d = nil
+		} else {
+			logger.Log.Infof("Compiled dictionary '%s' loaded in %s", cfg.Name, time.Since(start))
+		}
+	}
+
+	if d == nil {
+		if d, err = loadText(cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.Definitions != "" {
+		if err := d.loadDefinitions(cfg.Definitions); err != nil {
+			logger.Log.Warningf("Definitions of dictionary '%s' are not loaded (%s)", cfg.Name, err.Error())
+		}
+	}
+
+	if cfg.StartWords != "" {
+		if err := d.loadStartWords(cfg.StartWords); err != nil {
+			logger.Log.Warningf("Start words of dictionary '%s' are not loaded (%s)", cfg.Name, err.Error())
+		}
+	}
+
+	if cfg.Blocklist != "" {
+		if err := d.loadBlocklist(cfg.Blocklist); err != nil {
+			logger.Log.Warningf("Blocklist of dictionary '%s' is not loaded (%s)", cfg.Name, err.Error())
+		}
+	}
+
+	for _, rs := range rulesets {
+		f := NewFilter(rs)
+		if len(f.Require) != 0 && !d.tagged {
+			logger.Log.Warningf("Dictionary '%s' has no tags, ruleset '%s' requires tags and isn't available", cfg.Name, rs.Name)
+			continue
+		}
+		if !f.Empty() && !d.tagged {
+			logger.Log.Warningf("Dictionary '%s' has no tags, ruleset '%s' allows all its words", cfg.Name, rs.Name)
+		}
+		d.rulesets[rs.Name] = d.derive(rs.Name, f, nil)
+	}
+
+	return d, nil
+}
+
+/**
+ * @brief Read text dictionary
+ * @param[in] cfg Dictionary configuration
+ * @return d Pointer to the loaded Dictionary or error if it occured
  *
- * Reads words from the dictionary, fill wordsMap and wordsOfAS
+ * Reads words from the dictionary and normalize them,
+ * words with letters out of alphabet are skipped.
+ * Format of dictionary file is described in entry.go
  */
-func Init(as int, path string) error {
-	file, err := os.Open(path)
+func loadText(cfg conf.DictConf) (*Dictionary, error) {
+	file, err := os.Open(cfg.Path)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer file.Close()
 
-	wordMap = make(map[string]bool)
+	d, err := newDictionary(cfg)
+	if err != nil {
+		return nil, err
+	}
 
+	words := make(map[string]bool)
+	var keys []string
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
-		str := scanner.Text()
-		_, exist := wordMap[str]
-		if utf8.RuneCountInString(str) == as && !exist {
-			wordsOfAS = append(wordsOfAS, str)
+		str, entry, ok := parseLine(scanner.Text())
+		str = d.foldWord(strings.ToLower(str))
+		if str == "" || (cfg.Alphabet != "" && !d.inAlphabet(str)) {
+			continue
+		}
+
+		words[str] = true
+		for _, r := range str {
+			d.letters[r] = true
+		}
+
+		if ok {
+			d.tagged = true
+			entry.Lemma = d.foldWord(strings.ToLower(entry.Lemma))
+			keys = append(keys, str+sep+entry.Lemma+sep+strings.Join(entry.Tags, ","))
+		} else {
+			keys = append(keys, str+sep)
 		}
-		wordMap[str] = true
 	}
 
 	if err = scanner.Err(); err != nil {
-		return err
+		return nil, err
+	}
+
+	if len(words) == 0 {
+		return nil, errors.New(fmt.Sprintf("Dictionary '%s' is empty", cfg.Name))
+	}
+
+	d.size = len(words)
+	d.auto = BuildAutomaton(keys)
+	d.sortAlphabet()
+
+	return d, nil
+}
+
+/**
+ * @brief Create dictionary without words
+ * @param[in] cfg Dictionary configuration
+ * @return d Pointer to the new Dictionary or error if folding is malformed
+ */
+func newDictionary(cfg conf.DictConf) (*Dictionary, error) {
+	d := &Dictionary{
+		Name:     cfg.Name,
+		letters:  make(map[rune]bool),
+		fold:     make(map[rune]rune),
+		bySize:   make(map[int][]string),
+		rulesets: make(map[string]*Dictionary),
+	}
+
+	for from, to := range cfg.Fold {
+		if utf8.RuneCountInString(from) != 1 || utf8.RuneCountInString(to) != 1 {
+			return nil, errors.New(fmt.Sprintf("Wrong folding '%s' -> '%s' in dictionary '%s'", from, to, cfg.Name))
+		}
+		d.fold[[]rune(from)[0]] = []rune(to)[0]
 	}
 
-	return nil
+	for _, r := range strings.ToLower(cfg.Alphabet) {
+		d.letters[d.foldLetter(r)] = true
+	}
+	d.sortAlphabet()
+
+	return d, nil
+}
+
+/**
+ * @brief Fill sorted alphabet from set of letters
+ */
+func (d *Dictionary) sortAlphabet() {
+	d.alphabet = d.alphabet[:0]
+	for r := range d.letters {
+		d.alphabet = append(d.alphabet, r)
+	}
+	sort.Slice(d.alphabet, func(i, j int) bool { return d.alphabet[i] < d.alphabet[j] })
+}
+
+/**
+ * @brief Get dictionary filtered by ruleset
+ * @param[in] ruleset Name of ruleset (empty for full dictionary)
+ * @return d Filtered dictionary, ok is false if ruleset is unknown
+ */
+func (d *Dictionary) WithRuleset(ruleset string) (*Dictionary, bool) {
+	if ruleset == "" {
+		return d, true
+	}
+	res, ok := d.rulesets[ruleset]
+	return res, ok
+}
+
+/**
+ * @brief Names of rulesets of dictionary
+ * @return names Sorted slice of names
+ */
+func (d *Dictionary) Rulesets() []string {
+	var names []string
+	for name := range d.rulesets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+/**
+ * @brief Grammatical forms of the word
+ * @param[in] word Word from dictionary
+ * @return entries Forms of the word, empty if word has no tags or is not in dictionary
+ */
+func (d *Dictionary) Entries(word string) []Entry {
+	var entries []Entry
+	d.auto.Each(word+sep, func(suffix string) {
+		if suffix == "" {
+			return
+		}
+		fields := strings.SplitN(suffix, sep, 2)
+		entry := Entry{Lemma: fields[0]}
+		if len(fields) > 1 && fields[1] != "" {
+			entry.Tags = strings.Split(fields[1], ",")
+		}
+		entries = append(entries, entry)
+	})
+	return entries
+}
+
+/**
+ * @brief Number of words in full dictionary
+ */
+func (d *Dictionary) Size() int {
+	return d.size
+}
+
+/**
+ * @brief Get loaded dictionary by name
+ * @param[in] name Name of dictionary (language)
+ * @return d Pointer to the Dictionary, ok is false if it is not loaded
+ */
+func Get(name string) (*Dictionary, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	d, ok := dicts[name]
+	return d, ok
+}
+
+/**
+ * @brief Names of all loaded dictionaries
+ * @return names Sorted slice of names
+ */
+func Names() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	var names []string
+	for name := range dicts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+/**
+ * @brief Predicate, check if all letters of word are in alphabet
+ * @param[in] word Checking word
+ */
+func (d *Dictionary) inAlphabet(word string) bool {
+	for _, r := range word {
+		if !d.letters[r] {
+			return false
+		}
+	}
+	return true
+}
+
+/**
+ * @brief Replace letter by its folding if it exists
+ * @param[in] letter Letter to fold
+ */
+func (d *Dictionary) foldLetter(letter rune) rune {
+	if to, ok := d.fold[letter]; ok {
+		return to
+	}
+	return letter
+}
+
+/**
+ * @brief Replace all letters of word by their foldings
+ * @param[in] word Word to fold
+ */
+func (d *Dictionary) foldWord(word string) string {
+	if len(d.fold) == 0 {
+		return word
+	}
+	return strings.Map(d.foldLetter, word)
+}
+
+/**
+ * @brief Normalize player's word against alphabet
+ * @param[in] word Word typed by player
+ * @return word Lowercased and folded word or error with the reason of rejection
+ */
+func (d *Dictionary) Normalize(word string) (string, error) {
+	word = d.foldWord(strings.ToLower(strings.TrimSpace(word)))
+	if word == "" {
+		return "", errors.New("Empty word")
+	}
+
+	for _, r := range word {
+		if !d.letters[r] {
+			return "", d.letterError(r)
+		}
+	}
+	return word, nil
+}
+
+/**
+ * @brief Normalize player's letter against alphabet
+ * @param[in] str Letter typed by player
+ * @return letter Lowercased and folded letter or error with the reason of rejection
+ */
+func (d *Dictionary) NormalizeLetter(str string) (rune, error) {
+	str = strings.TrimSpace(str)
+	if utf8.RuneCountInString(str) != 1 {
+		return 0, errors.New("Exactly one letter is expected")
+	}
+
+	letter := d.foldLetter(unicode.ToLower([]rune(str)[0]))
+	if !d.letters[letter] {
+		return 0, d.letterError(letter)
+	}
+	return letter, nil
+}
+
+/**
+ * @brief Error about letter which is out of alphabet
+ * @param[in] letter Rejected letter
+ */
+func (d *Dictionary) letterError(letter rune) error {
+	if !unicode.IsLetter(letter) {
+		return errors.New(fmt.Sprintf("'%c' is not a letter", letter))
+	}
+	return errors.New(fmt.Sprintf("Letter '%c' is not in the '%s' alphabet: %s", letter, d.Name, string(d.alphabet)))
 }
 
 /**
  * @brief Predicate, check if word is in dictionary
  * @param[in] word Checking word
  * @return ok If ok is true, then word exists in dict
+ *
+ * Words added at runtime are filtered by ruleset too, by their tags from dictionary file
+ */
+func (d *Dictionary) CheckWord(word string) bool {
+	switch d.overlay.action(word) {
+	case ActionAdd:
+	case ActionRemove, ActionBan:
+		return false
+	default:
+		if strings.Contains(word, sep) || !d.inFile(word) {
+			return false
+		}
+	}
+	return d.filter.Empty() || d.filter.Match(d.Entries(word))
+}
+
+/**
+ * @brief Predicate, check if some word from dictionary starts with prefix
+ * @param[in] prefix Checking prefix
+ * @return ok If ok is true, then prefix can be continued to a word allowed by CheckWord
+ *
+ * Words filtered by ruleset, removed or banned at runtime don't continue prefix
  */
-func CheckWord(word string) bool {
-	_, ok := wordMap[word]
-	return ok
+func (d *Dictionary) CheckPrefix(prefix string) bool {
+	if d.overlay != nil && d.overlay.prefixes[prefix] {
+		for word, action := range d.overlay.actions {
+			if action == ActionAdd && len(word) > len(prefix) && strings.HasPrefix(word, prefix) && d.CheckWord(word) {
+				return true
+			}
+		}
+	}
+	if d.filter.Empty() && !d.overlay.removes() {
+		return d.auto.continues(prefix, sep[0])
+	}
+
+	return d.auto.Any(prefix, func(suffix string) bool {
+		n := strings.Index(suffix, sep)
+		return n > 0 && d.CheckWord(prefix+suffix[:n])
+	})
 }
 
 /**
- * @bried Return random word with AreaSize length
- * @return word Random word with AreaSize length
+ * @brief Predicate, check if some word of dictionary file or added word starts with prefix
+ * @param[in] prefix Checking prefix
+ * @return ok If ok is false, then no word allowed by CheckWord starts with prefix
+ *
+ * Fast check for search of words, ruleset and removed words aren't considered
  */
-func RandWordOfAS() string {
-	rand.Seed(time.Now().UnixNano())
-	return wordsOfAS[rand.Intn(len(wordsOfAS))]
+func (d *Dictionary) HasPrefix(prefix string) bool {
+	if d.overlay != nil && d.overlay.prefixes[prefix] {
+		return true
+	}
+	return d.auto.continues(prefix, sep[0])
+}
+
+/**
+ * @brief Letters of the language
+ * @return alphabet Sorted slice of letters
+ */
+func (d *Dictionary) Alphabet() []rune {
+	return d.alphabet
+}
+
+/**
+ * @brief Return random word with AreaSize length
+ * @param[in] as Length side of the playing area
+ * @param[in] r Random generator of the game session
+ * @return word Random word or empty string if there are no words with such length
+ */
+func (d *Dictionary) RandWordOfAS(as int, r *rand.Rand) string {
+	words := d.wordsOfSize(as)
+	if len(words) == 0 {
+		return ""
+	}
+	return words[r.Intn(len(words))]
+}
+
+/**
+ * @brief Words with given length
+ * @param[in] size Length of words
+ * @return words Sorted slice of words allowed by ruleset
+ *
+ * Words are collected from automaton on first call and cached
+ */
+func (d *Dictionary) wordsOfSize(size int) []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if words, ok := d.bySize[size]; ok {
+		return words
+	}
+
+	var words []string
+	d.auto.Each("", func(key string) {
+		word := key[:strings.Index(key, sep)]
+		if utf8.RuneCountInString(word) != size || (len(words) > 0 && words[len(words)-1] == word) {
+			return
+		}
+		if d.CheckWord(word) {
+			words = append(words, word)
+		}
+	})
+
+	if d.overlay != nil {
+		for word, action := range d.overlay.actions {
+			if action == ActionAdd && !d.inFile(word) && utf8.RuneCountInString(word) == size {
+				words = append(words, word)
+			}
+		}
+		sort.Strings(words)
+	}
+
+	d.bySize[size] = words
+	return words
 }
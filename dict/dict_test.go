@@ -0,0 +1,95 @@
+/**
+ * @file dict_test.go
+ * @brief Tests of dictionary checks
+ */
+
+package dict
+
+import (
+	// System
+	"testing"
+	// Third-party
+	// Project
+)
+
+/**
+ * @brief Prefix is continued only by words allowed by ruleset and runtime changes
+ */
+func TestCheckPrefix(t *testing.T) {
+	cfg, cleanup := testDict(t, "абажур\tабажур\tNOUN,inan,masc,sing,nomn\nабажурный\tабажурный\tADJF,sing,nomn\n")
+	defer cleanup()
+
+	d, err := loadText(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !d.CheckPrefix("абаж") || !d.CheckPrefix("абажурн") || d.CheckPrefix("абажурный") {
+		t.Error("Prefixes of full dictionary are wrong")
+	}
+
+	nouns := d.derive("nouns", Filter{Require: []string{"NOUN"}}, nil)
+	if !nouns.CheckPrefix("абаж") || nouns.CheckPrefix("абажурн") {
+		t.Error("Prefix of filtered word is allowed by ruleset")
+	}
+	if !nouns.HasPrefix("абажурн") {
+		t.Error("Fast check doesn't allow prefix of word from file")
+	}
+
+	removed := d.derive("", Filter{}, NewOverlay().with("абажур", ActionRemove))
+	if !removed.CheckPrefix("абаж") {
+		t.Error("Prefix of remaining word isn't allowed")
+	}
+	banned := d.derive("", Filter{}, removed.overlay.with("абажурный", ActionBan))
+	if banned.CheckPrefix("абаж") || banned.CheckPrefix("аба") {
+		t.Error("Prefix of removed and banned words is allowed")
+	}
+
+	added := d.derive("", Filter{}, banned.overlay.with("абажурчик", ActionAdd))
+	if !added.CheckPrefix("абаж") || !added.CheckPrefix("абажурч") || added.CheckPrefix("абажурн") {
+		t.Error("Prefixes of added word are wrong")
+	}
+}
+
+/**
+ * @brief Words added at runtime are filtered by ruleset
+ */
+func TestCheckAddedWord(t *testing.T) {
+	cfg, cleanup := testDict(t, "абажур\tабажур\tNOUN,inan,masc,sing,nomn\nабажурный\tабажурный\tADJF,sing,nomn\n")
+	defer cleanup()
+
+	d, err := loadText(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	o := NewOverlay().with("абажурный", ActionAdd).with("абажур", ActionAdd)
+	nouns := d.derive("nouns", Filter{Exclude: []string{"ADJF"}}, o)
+	if nouns.CheckWord("абажурный") {
+		t.Error("Added word is allowed against ruleset")
+	}
+	if !nouns.CheckWord("абажур") {
+		t.Error("Added word allowed by ruleset isn't allowed")
+	}
+}
+
+/**
+ * @brief Words without tags are allowed only by rulesets without required tags
+ */
+func TestFilterUntagged(t *testing.T) {
+	cfg, cleanup := testDict(t, "абажур\tабажур\tNOUN,inan,masc,sing,nomn\nбалда\n")
+	defer cleanup()
+
+	d, err := loadText(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nouns := d.derive("nouns", Filter{Require: []string{"NOUN"}}, nil)
+	if !nouns.CheckWord("абажур") || nouns.CheckWord("балда") {
+		t.Error("Ruleset with required tags allows word without tags")
+	}
+	free := d.derive("free", Filter{Exclude: []string{"Name"}}, nil)
+	if !free.CheckWord("абажур") || !free.CheckWord("балда") {
+		t.Error("Ruleset without required tags doesn't allow word without tags")
+	}
+}
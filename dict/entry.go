@@ -0,0 +1,135 @@
+/**
+ * @file entry.go
+ * @brief Grammatical information about words
+ *
+ * Dictionary file contains one word per line in one of two formats:
+ * 	1) Plain: "word"
+ * 	2) Tagged: "word<TAB>lemma<TAB>tag,tag,..." (e.g. "абажуры	абажур	NOUN,inan,masc,plur,nomn")
+ * Lines starting with '#' are comments. A word can be listed several times with different tags.
+ */
+
+package dict
+
+import (
+	// System
+	"strings"
+
+	// Third-party
+
+	// Project
+	"github.com/BaldaGo/balda-go/conf"
+)
+
+/**
+ * @class Entry
+ * @brief Class, provides one grammatical form of the word
+ */
+type Entry struct {
+	Lemma string   ///< Initial form of the word
+	Tags  []string ///< Grammatical tags (part of speech, number, case, ...)
+}
+
+/**
+ * @class Filter
+ * @brief Class, provides policy which words of the dictionary are allowed by ruleset
+ *
+ * Word is allowed if any of its entries has all required tags and has no excluded tags.
+ * Words without tags (plain format) are allowed only if filter doesn't require tags
+ */
+type Filter struct {
+	Require []string ///< Tags which entry must have
+	Exclude []string ///< Tags which entry must not have
+}
+
+/**
+ * @brief Parse line of dictionary file
+ * @param[in] line Line of dictionary file
+ * @return word Word from line, empty string if line has no word
+ * @return entry Grammatical information about word
+ * @return tagged Flag if line is in tagged format
+ */
+func parseLine(line string) (string, Entry, bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", Entry{}, false
+	}
+
+	fields := strings.Split(line, "\t")
+	if len(fields) == 1 {
+		return line, Entry{}, false
+	}
+
+	entry := Entry{Lemma: strings.TrimSpace(fields[1])}
+	if len(fields) > 2 {
+		for _, tag := range strings.Split(fields[2], ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				entry.Tags = append(entry.Tags, tag)
+			}
+		}
+	}
+
+	return strings.TrimSpace(fields[0]), entry, true
+}
+
+/**
+ * @brief Predicate, check if entry has tag
+ * @param[in] tag Grammatical tag
+ */
+func (e Entry) Has(tag string) bool {
+	for _, t := range e.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * @brief Create filter from ruleset configuration
+ * @param[in] cfg Ruleset configuration
+ */
+func NewFilter(cfg conf.RulesetConf) Filter {
+	return Filter{Require: cfg.Require, Exclude: cfg.Exclude}
+}
+
+/**
+ * @brief Predicate, check if filter allows any words
+ */
+func (f Filter) Empty() bool {
+	return len(f.Require) == 0 && len(f.Exclude) == 0
+}
+
+/**
+ * @brief Predicate, check if word with given entries is allowed by filter
+ * @param[in] entries All grammatical forms of the word
+ */
+func (f Filter) Match(entries []Entry) bool {
+	if len(entries) == 0 {
+		return len(f.Require) == 0
+	}
+
+	for _, e := range entries {
+		if f.matchEntry(e) {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * @brief Predicate, check if one entry is allowed by filter
+ * @param[in] e Grammatical form of the word
+ */
+func (f Filter) matchEntry(e Entry) bool {
+	for _, tag := range f.Require {
+		if !e.Has(tag) {
+			return false
+		}
+	}
+	for _, tag := range f.Exclude {
+		if e.Has(tag) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,295 @@
+/**
+ * @file overlay.go
+ * @brief Runtime changes of dictionary
+ *
+ * Words added, removed or banned by admins are layered over loaded dictionary.
+ * Every change creates a new Dictionary object and swaps it in registry,
+ * so games keep using the old one until they ask for the dictionary again
+ */
+
+package dict
+
+import (
+	// System
+	"errors"
+	"fmt"
+	"sync"
+
+	// Third-party
+
+	// Project
+	"github.com/BaldaGo/balda-go/conf"
+)
+
+/**
+ * @brief enum of overlay actions
+ */
+const (
+	ActionAdd    = "add"    ///< Word is added to dictionary
+	ActionRemove = "remove" ///< Word is removed from dictionary
+	ActionBan    = "ban"    ///< Word is removed from dictionary and can't be added by players
+)
+
+/**
+ * @class Overlay
+ * @brief Class, provides words changed at runtime
+ *
+ * Overlay is never changed after it is attached to Dictionary
+ */
+type Overlay struct {
+	actions  map[string]string ///< Action by word
+	prefixes map[string]bool   ///< Proper prefixes of added words
+}
+
+var registry sync.RWMutex // Lock of dicts while swapping
+var changing sync.Mutex   // Lock of runtime changes, so they don't overwrite each other
+
+/**
+ * @brief Create empty overlay
+ */
+func NewOverlay() *Overlay {
+	return &Overlay{actions: make(map[string]string), prefixes: make(map[string]bool)}
+}
+
+/**
+ * @brief Copy overlay with changed action of word
+ * @param[in] word Normalized word
+ * @param[in] action One of ActionAdd, ActionRemove, ActionBan
+ * @return o New overlay
+ */
+func (o *Overlay) with(word string, action string) *Overlay {
+	res := NewOverlay()
+	if o != nil {
+		for w, a := range o.actions {
+			res.actions[w] = a
+		}
+	}
+	res.actions[word] = action
+	res.index()
+	return res
+}
+
+/**
+ * @brief Fill prefixes of added words
+ */
+func (o *Overlay) index() {
+	o.prefixes = make(map[string]bool)
+	for w, a := range o.actions {
+		if a != ActionAdd {
+			continue
+		}
+		runes := []rune(w)
+		for i := 1; i < len(runes); i++ {
+			o.prefixes[string(runes[:i])] = true
+		}
+	}
+}
+
+/**
+ * @brief Action of word in overlay
+ * @param[in] word Normalized word
+ * @return action Action or empty string if word is not changed
+ */
+func (o *Overlay) action(word string) string {
+	if o == nil {
+		return ""
+	}
+	return o.actions[word]
+}
+
+/**
+ * @brief Predicate, check if overlay removes or bans some words
+ */
+func (o *Overlay) removes() bool {
+	if o == nil {
+		return false
+	}
+	for _, a := range o.actions {
+		if a != ActionAdd {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * @brief Predicate, check if word is banned
+ * @param[in] word Normalized word
+ */
+func (d *Dictionary) IsBanned(word string) bool {
+	return d.overlay.action(word) == ActionBan
+}
+
+/**
+ * @brief Predicate, check if word is known by the dictionary file (without runtime changes)
+ * @param[in] word Normalized word
+ */
+func (d *Dictionary) inFile(word string) bool {
+	return d.auto.HasPrefix(word + sep)
+}
+
+/**
+ * @brief Create dictionary with the same words and another filter or overlay
+ * @param[in] ruleset Name of ruleset
+ * @param[in] f Filter of ruleset
+ * @param[in] o Overlay of runtime changes
+ * @return d New dictionary, it shares words and alphabet with this one
+ */
+func (d *Dictionary) derive(ruleset string, f Filter, o *Overlay) *Dictionary {
+	return &Dictionary{
+		Name:     d.Name,
+		Ruleset:  ruleset,
+		auto:     d.auto,
+		filter:   f,
+		overlay:  o,
+		tagged:   d.tagged,
+		size:     d.size,
+		alphabet: d.alphabet,
+		letters:  d.letters,
+		fold:     d.fold,
+		bySize:   make(map[int][]string),
+		rulesets: d.rulesets,
+
+		definitions: d.definitions,
+		startWords:  d.startWords,
+		blocked:     d.blocked,
+	}
+}
+
+/**
+ * @brief Create dictionary with the same words and rulesets and another overlay
+ * @param[in] o Overlay of runtime changes
+ * @return d New full dictionary
+ */
+func (d *Dictionary) withOverlay(o *Overlay) *Dictionary {
+	res := d.derive("", Filter{}, o)
+	res.rulesets = make(map[string]*Dictionary)
+	for name, view := range d.rulesets {
+		res.rulesets[name] = res.derive(name, view.filter, o)
+	}
+	return res
+}
+
+/**
+ * @brief Swap dictionary in registry
+ * @param[in] d New dictionary
+ */
+func swap(d *Dictionary) {
+	registry.Lock()
+	dicts[d.Name] = d
+	registry.Unlock()
+}
+
+/**
+ * @brief Change word of dictionary at runtime
+ * @param[in] name Name of dictionary (language)
+ * @param[in] word Word typed by admin
+ * @param[in] action One of ActionAdd, ActionRemove, ActionBan
+ * @return word Normalized word or error if it occured
+ */
+func Change(name string, word string, action string) (string, error) {
+	if action != ActionAdd && action != ActionRemove && action != ActionBan {
+		return "", errors.New(fmt.Sprintf("Unknown action '%s'", action))
+	}
+
+	changing.Lock()
+	defer changing.Unlock()
+
+	d, ok := Get(name)
+	if !ok {
+		return "", errors.New(fmt.Sprintf("Unknown language '%s'", name))
+	}
+
+	word, err := d.Normalize(word)
+	if err != nil {
+		return "", err
+	}
+
+	swap(d.withOverlay(d.overlay.with(word, action)))
+	return word, nil
+}
+
+/**
+ * @brief Add word to dictionary at runtime
+ * @param[in] name Name of dictionary (language)
+ * @param[in] word Word typed by admin
+ * @return word Normalized word or error if it occured
+ */
+func AddWord(name string, word string) (string, error) {
+	return Change(name, word, ActionAdd)
+}
+
+/**
+ * @brief Remove word from dictionary at runtime
+ * @param[in] name Name of dictionary (language)
+ * @param[in] word Word typed by admin
+ * @return word Normalized word or error if it occured
+ */
+func RemoveWord(name string, word string) (string, error) {
+	return Change(name, word, ActionRemove)
+}
+
+/**
+ * @brief Ban word at runtime
+ * @param[in] name Name of dictionary (language)
+ * @param[in] word Word typed by admin
+ * @return word Normalized word or error if it occured
+ */
+func BanWord(name string, word string) (string, error) {
+	return Change(name, word, ActionBan)
+}
+
+/**
+ * @brief Load dictionary files again and apply runtime changes
+ * @param[in] cfg Dictionary configuration
+ * @param[in] rulesets Rulesets to filter dictionary
+ * @param[in] actions Actions by word (e.g. stored in database)
+ * @return d New dictionary or error if it occured, old dictionary is kept on error
+ */
+func Reload(cfg conf.DictConf, rulesets []conf.RulesetConf, actions map[string]string) (*Dictionary, error) {
+	changing.Lock()
+	defer changing.Unlock()
+
+	d, err := load(cfg, rulesets)
+	if err != nil {
+		return nil, err
+	}
+
+	d = d.withOverlay(d.overlayOf(actions))
+	swap(d)
+	return d, nil
+}
+
+/**
+ * @brief Replace runtime changes of loaded dictionary
+ * @param[in] name Name of dictionary (language)
+ * @param[in] actions Actions by word (e.g. stored in database)
+ * @return err Error if dictionary is not loaded
+ */
+func Apply(name string, actions map[string]string) error {
+	changing.Lock()
+	defer changing.Unlock()
+
+	d, ok := Get(name)
+	if !ok {
+		return errors.New(fmt.Sprintf("Unknown language '%s'", name))
+	}
+
+	swap(d.withOverlay(d.overlayOf(actions)))
+	return nil
+}
+
+/**
+ * @brief Create overlay from actions by word
+ * @param[in] actions Actions by word, words are normalized and wrong ones are skipped
+ */
+func (d *Dictionary) overlayOf(actions map[string]string) *Overlay {
+	o := NewOverlay()
+	for word, action := range actions {
+		if word, err := d.Normalize(word); err == nil {
+			o.actions[word] = action
+		}
+	}
+	o.index()
+	return o
+}
@@ -0,0 +1,80 @@
+/**
+ * @file startwords.go
+ * @brief Curated start words
+ *
+ * Start words are loaded from text file next to the dictionary.
+ * Format: one word per line, lines starting with '#' are comments
+ */
+
+package dict
+
+import (
+	// System
+	"bufio"
+	"math/rand"
+	"os"
+	"strings"
+	"unicode/utf8"
+	// Third-party
+	// Project
+)
+
+/**
+ * @brief Read start words file
+ * @param[in] path Path to start words file
+ * @return err Error if it occured
+ *
+ * Words with letters out of alphabet are skipped
+ */
+func (d *Dictionary) loadStartWords(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	d.startWords = nil
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if word, err := d.Normalize(line); err == nil {
+			d.startWords = append(d.startWords, word)
+		}
+	}
+
+	return scanner.Err()
+}
+
+/**
+ * @brief Curated start words with given length
+ * @param[in] size Length of words
+ * @return words Words allowed by ruleset and runtime changes
+ */
+func (d *Dictionary) StartWords(size int) []string {
+	var words []string
+	for _, word := range d.startWords {
+		if utf8.RuneCountInString(word) == size && d.CheckWord(word) {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+/**
+ * @brief Return random curated start word
+ * @param[in] size Length of word
+ * @param[in] r Random generator of the game session
+ * @return word Random word or empty string if there are no curated words with such length
+ */
+func (d *Dictionary) RandStartWord(size int, r *rand.Rand) string {
+	words := d.StartWords(size)
+	if len(words) == 0 {
+		return ""
+	}
+	return words[r.Intn(len(words))]
+}
@@ -27,6 +27,32 @@ type Options struct {
 	LogFile    flags.Filename `long:"logfile" short:"l" description:"Filename of log file"`
 	ConfigFile flags.Filename `long:"config" short:"c" description:"Filename of configuration json file (default: config.json)"`
 	Debug      bool           `long:"debug" short:"d" description:"Debug flug. If given, server runs in debug mode"`
+
+	CompileDict     CompileDictCommand     `command:"compile-dict" description:"Compile text dictionaries into binary files for fast startup"`
+	GeneratePuzzles GeneratePuzzlesCommand `command:"generate-puzzles" description:"Generate puzzles with unique best move for daily puzzle and training"`
+
+	Command string `no-flag:"true"` ///< Name of given command, empty if server should run
+}
+
+/**
+ * @class CompileDictCommand
+ * @brief Arguments of compile-dict command
+ */
+type CompileDictCommand struct {
+	Name   string         `long:"name" short:"n" description:"Name of dictionary from configuration (default: all dictionaries)"`
+	Output flags.Filename `long:"output" short:"o" description:"Filename of compiled dictionary, needs --name if several dictionaries are configured (default: Compiled from configuration)"`
+}
+
+/**
+ * @class GeneratePuzzlesCommand
+ * @brief Arguments of generate-puzzles command
+ */
+type GeneratePuzzlesCommand struct {
+	Count      int            `long:"count" short:"n" default:"100" description:"Number of puzzles"`
+	Difficulty string         `long:"difficulty" choice:"easy" choice:"medium" choice:"hard" description:"Difficulty of puzzles (default: any)"`
+	Measure    string         `long:"measure" choice:"length" choice:"rarity" default:"length" description:"Measure of difficulty: length of answer or its popularity among players (needs database)"`
+	Seed       int64          `long:"seed" description:"Seed of random generator (default: current time)"`
+	Output     flags.Filename `long:"output" short:"o" description:"Filename of puzzles (default: Puzzles from configuration)"`
 }
 
 /**
@@ -36,6 +62,7 @@ type Options struct {
 func New() *Options {
 	opts := new(Options)
 	p := flags.NewParser(opts, flags.HelpFlag)
+	p.SubcommandsOptional = true
 	args, err := p.Parse()
 
 	if len(args) > 0 {
@@ -51,6 +78,10 @@ func New() *Options {
 		}
 	}
 
+	if p.Active != nil {
+		opts.Command = p.Active.Name
+	}
+
 	return opts
 }
 
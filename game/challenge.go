@@ -0,0 +1,300 @@
+/**
+ * @file challenge.go
+ * @brief Word challenge
+ *
+ * Other players can challenge the word of the last move within a short window,
+ * e.g. if it is a dubious word of the dictionary. Players except author of the word vote
+ * whether the move stands, admin can decide instead of them. When voting time is over,
+ * challenge is decided by timer. If the challenge succeeds, the move is rolled back
+ */
+
+package game
+
+import (
+	// System
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	// Third-party
+
+	// Project
+	"github.com/BaldaGo/balda-go/db"
+	"github.com/BaldaGo/balda-go/logger"
+)
+
+/**
+ * @brief enum of votes
+ */
+const (
+	VoteKeep = "keep" ///< Move stands
+	VoteUndo = "undo" ///< Move is rolled back
+)
+
+/**
+ * @class lastMove
+ * @brief Class, provides the last move, which can be challenged
+ */
+type lastMove struct {
+	user     string    ///< User, who made move
+	x        int       ///< Horisontal coordinate of the new letter (column)
+	y        int       ///< Vertical coordinate of the new letter (row)
+	letter   string    ///< New letter
+	word     string    ///< Word made with the new letter
+	score    int       ///< Score given for the word
+	skipped  int       ///< Number of skips before move
+	deadline time.Time ///< Time when move can't be challenged anymore
+}
+
+/**
+ * @class challenge
+ * @brief Class, provides state of running challenge
+ */
+type challenge struct {
+	challenger string            ///< User, who challenged the word
+	votes      map[string]string ///< Vote by user
+	deadline   time.Time         ///< Time when voting is over
+	timer      *time.Timer       ///< Timer, which decides challenge when voting is over
+}
+
+/**
+ * @brief Remember the last move, so it can be challenged
+ * @param[in] m Move
+ */
+func (game *Game) rememberMove(m lastMove) {
+	m.deadline = time.Now().Add(game.ChallengeTime)
+	game.last = &m
+	game.challenge = nil
+}
+
+/**
+ * @brief Challenge the word of the last move
+ * @param[in] user User, who challenges
+ * @return Same values as Continue
+ *
+ * Challenger votes for undo, author of the word doesn't vote
+ */
+func (game *Game) startChallenge(user string) (bool, string, error) {
+	if game.challenge != nil {
+		return true, fmt.Sprintf("Word '%s' is already challenged, vote: vote keep|undo", game.last.word), nil
+	}
+	if game.last == nil || time.Now().After(game.last.deadline) {
+		return true, "Nothing to challenge, the last word can't be challenged anymore", nil
+	}
+	if game.last.user == user {
+		return true, "You can't challenge your own word", nil
+	}
+
+	c := &challenge{
+		challenger: user,
+		votes:      map[string]string{user: VoteUndo},
+		deadline:   time.Now().Add(game.ChallengeTime),
+	}
+	c.timer = time.AfterFunc(game.ChallengeTime, func() { game.expireChallenge(c) })
+	game.challenge = c
+	logger.Log.Infof("%s challenged word '%s' of %s", user, game.last.word, game.last.user)
+
+	msg := fmt.Sprintf("I challenge word '%s' of %s! Vote in %s: vote keep|undo", game.last.word, game.last.user, game.ChallengeTime)
+	if res, ok := game.decideChallenge(false); ok {
+		return true, strings.Join([]string{msg, res}, "\n\r"), nil
+	}
+	return true, msg, nil
+}
+
+/**
+ * @brief Vote in running challenge
+ * @param[in] args Vote (keep or undo)
+ * @param[in] user User, who votes
+ * @return Same values as Continue
+ */
+func (game *Game) vote(args []string, user string) (bool, string, error) {
+	if game.challenge == nil {
+		return true, "There is no challenge now", nil
+	}
+	if len(args) == 0 || (args[0] != VoteKeep && args[0] != VoteUndo) {
+		return true, "Not correct command, use: vote keep|undo", nil
+	}
+	if user == game.last.user {
+		return true, "You can't vote for your own word", nil
+	}
+	if _, ok := game.challenge.votes[user]; ok {
+		return true, "You have already voted", nil
+	}
+
+	game.challenge.votes[user] = args[0]
+	if res, ok := game.decideChallenge(false); ok {
+		return true, res, nil
+	}
+	return true, fmt.Sprintf("I vote %s (%d/%d)", args[0], len(game.challenge.votes), game.voters()), nil
+}
+
+/**
+ * @brief Number of users, who vote in challenge: all users except bots and author of the word
+ */
+func (game *Game) voters() int {
+	if game.IsBot(game.last.user) {
+		return game.humans()
+	}
+	return game.humans() - 1
+}
+
+/**
+ * @brief Decide challenge by votes given in time, when voting is over
+ * @param[in] c Challenge of timer
+ *
+ * Called by timer, result and moves of bots after it are sent by Notifier
+ */
+func (game *Game) expireChallenge(c *challenge) {
+	game.mu.Lock()
+	if game.challenge != c {
+		game.mu.Unlock()
+		return
+	}
+	res, _ := game.decideChallenge(true)
+	res, play := game.afterChallenge(res)
+	game.mu.Unlock()
+
+	if game.Notifier != nil {
+		game.Notifier(nil, res, !play)
+	}
+}
+
+/**
+ * @brief Moves of bots after challenge is decided
+ * @param[in] res Result of challenge
+ * @return res Result of challenge and moves of bots
+ * @return play False if game is over
+ */
+func (game *Game) afterChallenge(res string) (string, bool) {
+	if !game.botTurn() {
+		return res, true
+	}
+	play, moves, err := game.playBots()
+	if err != nil {
+		logger.Log.Critical(err.Error())
+	}
+	return strings.Join([]string{res, moves}, "\n\r"), play
+}
+
+/**
+ * @brief Answer to any move while challenge is running
+ * @return Same values as Continue
+ *
+ * When voting is over, challenge is decided by votes given in time
+ */
+func (game *Game) waitChallenge() (bool, string, error) {
+	if time.Now().After(game.challenge.deadline) {
+		res, _ := game.decideChallenge(true)
+		return true, res, nil
+	}
+	return true, fmt.Sprintf("Word '%s' is challenged, wait until it is decided or vote: vote keep|undo", game.last.word), nil
+}
+
+/**
+ * @brief Decide challenge by votes
+ * @param[in] final Flag if voting is over
+ * @return res Result of challenge
+ * @return ok False if challenge isn't decided yet
+ *
+ * Majority of all users except bots and author of the word decides.
+ * If votes are tied, admin can decide until voting is over, then move stands
+ */
+func (game *Game) decideChallenge(final bool) (string, bool) {
+	keep, undo := 0, 0
+	for _, v := range game.challenge.votes {
+		if v == VoteUndo {
+			undo++
+		} else {
+			keep++
+		}
+	}
+
+	switch {
+	case 2*undo > game.voters():
+		return game.resolveChallenge(true, fmt.Sprintf("Players voted %d:%d.", undo, keep)), true
+	case 2*keep > game.voters() || final:
+		return game.resolveChallenge(false, fmt.Sprintf("Players voted %d:%d.", undo, keep)), true
+	}
+	return "", false
+}
+
+/**
+ * @brief Admin's decision of running challenge
+ * @param[in] decision VoteKeep or VoteUndo
+ * @param[in] admin Admin's login
+ * @return res Result of challenge
+ * @return err Error if there is no challenge or decision is unknown
+ *
+ * Admin decides from another session, so decision is made under lock of the game.
+ * Result is sent to users of game by Notifier
+ */
+func (game *Game) RuleChallenge(decision string, admin string) (string, error) {
+	if decision != VoteKeep && decision != VoteUndo {
+		return "", errors.New("Not correct decision, use: keep or undo")
+	}
+
+	game.mu.Lock()
+	if game.challenge == nil {
+		game.mu.Unlock()
+		return "", errors.New("There is no challenge now")
+	}
+	res := game.resolveChallenge(decision == VoteUndo, fmt.Sprintf("Admin %s decided.", admin))
+	res, play := game.afterChallenge(res)
+	game.mu.Unlock()
+
+	if game.Notifier != nil {
+		game.Notifier(nil, res, !play)
+	}
+	return res, nil
+}
+
+/**
+ * @brief Finish challenge and roll back the move if it failed
+ * @param[in] undo Flag if move is rolled back
+ * @param[in] reason Message, who decided
+ * @return res Result of challenge
+ */
+func (game *Game) resolveChallenge(undo bool, reason string) string {
+	m := game.last
+	game.challenge.timer.Stop()
+	game.challenge = nil
+	game.last = nil
+
+	if !undo {
+		logger.Log.Infof("Word '%s' of %s stands", m.word, m.user)
+		return fmt.Sprintf("%s Word '%s' of %s stands", reason, m.word, m.user)
+	}
+
+	if err := game.undoMove(m); err != nil {
+		logger.Log.Critical(err.Error())
+	}
+	logger.Log.Infof("Word '%s' of %s is rolled back", m.word, m.user)
+	return strings.Join([]string{
+		fmt.Sprintf("%s Word '%s' of %s is rolled back (-%d)", reason, m.word, m.user, m.score),
+		game.square.StrPrintArea(),
+	}, "\n\r")
+}
+
+/**
+ * @brief Roll back the last move
+ * @param[in] m Move
+ * @return err Error if it occured
+ *
+ * Letter and word are removed from area, score and lexicon of user are reverted.
+ * Turn order isn't changed, so user loses the move
+ */
+func (game *Game) undoMove(m *lastMove) error {
+	if !game.square.Undo() {
+		return errors.New("Nothing to undo on area")
+	}
+	game.scoreMap[m.user] -= m.score
+	game.skipped = m.skipped
+
+	if game.Ranked {
+		if err := db.RemoveWord(m.user, m.word, game.Language); err != nil {
+			return err
+		}
+	}
+	return game.logMove(m.user, db.MoveUndo, m.x, m.y, m.letter, m.word)
+}
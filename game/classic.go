@@ -0,0 +1,269 @@
+/**
+ * @file classic.go
+ * @brief Classic balda
+ *
+ * Letter-by-letter game without area: players add one letter at a time
+ * to the end of a growing fragment. The player who completes a word,
+ * makes a fragment which no word starts with or can't extend the fragment
+ * gets the next letter of "БАЛДА".
+ * The player may add a letter without a word in mind, then the next player
+ * can challenge him and he must claim a word, which starts with the fragment.
+ */
+
+package game
+
+import (
+	// System
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	// Third-party
+
+	// Project
+	"github.com/BaldaGo/balda-go/logger"
+)
+
+/**
+ * @brief enum of game modes
+ */
+const (
+	ModeGrid    = "grid"    ///< Words are made on the area
+	ModeClassic = "classic" ///< Letters are added to the fragment
+)
+
+const (
+	classicLoser   = "БАЛДА" ///< Letters of penalties, the player who collects all of them loses
+	classicMinWord = 3       ///< Shorter words don't complete the fragment
+)
+
+/**
+ * @class classic
+ * @brief Class, provides state of classic game
+ */
+type classic struct {
+	fragment   []rune         ///< Letters added in the current round
+	last       string         ///< User, who added the last letter
+	challenger string         ///< User, who challenged the last letter (empty if there is no challenge)
+	penalties  map[string]int ///< Number of penalties by user
+}
+
+var modes = []string{ModeGrid, ModeClassic, ModeHunt} ///< All game modes
+
+/**
+ * @brief Predicate, check if game mode is known
+ * @param[in] mode Name of mode
+ */
+func IsMode(mode string) bool {
+	for _, m := range modes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * @brief Show or choose mode of the game
+ * @param[in] args Name of mode, if it is empty, shows available modes
+ * @return Same values as Continue
+ *
+ * Mode can be changed only before game starts
+ */
+func (game *Game) mode(args []string) (bool, string, error) {
+	if len(args) == 0 || args[0] == "" {
+		return true, fmt.Sprintf("Mode: %s. Available: %s", game.Mode, strings.Join(modes, ", ")), nil
+	}
+	if game.onStart {
+		return true, "Game already started, mode can't be changed", nil
+	}
+	if !IsMode(args[0]) {
+		return true, fmt.Sprintf("Unknown mode '%s'. Available: %s", args[0], strings.Join(modes, ", ")), nil
+	}
+
+	if args[0] != ModeGrid && len(game.bots) > 0 {
+		return true, fmt.Sprintf("Bots play only in %s mode", ModeGrid), nil
+	}
+
+	game.Mode = args[0]
+	return true, fmt.Sprintf("Mode of the game changed to %s", game.Mode), nil
+}
+
+/**
+ * @brief Start classic game without penalties
+ */
+func (game *Game) startClassic() {
+	game.classic = classic{penalties: make(map[string]int)}
+	for _, u := range game.users {
+		game.classic.penalties[u] = 0
+	}
+}
+
+/**
+ * @brief Fragment and penalties of classic game
+ */
+func (game *Game) fragment() string {
+	lines := []string{fmt.Sprintf("Fragment: %s", strings.ToUpper(string(game.classic.fragment)))}
+	if game.classic.challenger != "" {
+		lines = append(lines, fmt.Sprintf("%s challenged %s", game.classic.challenger, game.classic.last))
+	}
+	for _, u := range game.users {
+		lines = append(lines, fmt.Sprintf("%s : %s", u, game.penaltyLetters(u)))
+	}
+	return strings.Join(lines, "\n\r")
+}
+
+/**
+ * @brief Collected letters of "БАЛДА"
+ * @param[in] user User's login
+ */
+func (game *Game) penaltyLetters(user string) string {
+	letters := []rune(classicLoser)
+	return string(letters[:game.classic.penalties[user]])
+}
+
+/**
+ * @brief Make move of classic game
+ * @param[in] arr Command with arguments
+ * @param[in] user User, whose step is now
+ * @return Same values as Continue
+ *
+ * Commands:
+ * 	add <letter>  Add letter to the end of fragment
+ * 	challenge     Ask previous player to claim a word
+ * 	claim <word>  Claim a word, which starts with fragment (if you are challenged)
+ * 	skip          Give up the round, you can't extend fragment
+ */
+func (game *Game) classicMove(arr []string, user string) (bool, string, error) {
+	game.refreshDictionary()
+	c := &game.classic
+
+	if c.challenger != "" && arr[0] != "claim" {
+		return true, "You are challenged, claim a word: claim <word>", nil
+	}
+
+	switch arr[0] {
+	case "add":
+		if len(arr) < 2 {
+			return true, "Not correct command, letter is expected", nil
+		}
+		letter, err := game.dictionary.NormalizeLetter(arr[1])
+		if err != nil {
+			return true, fmt.Sprintf("Invalid letter: %s. Try again.", err.Error()), nil
+		}
+		return game.addLetter(letter, user)
+	case "challenge":
+		if len(c.fragment) == 0 {
+			return true, "Nothing to challenge, fragment is empty", nil
+		}
+		c.challenger = user
+		game.stepUser = game.userIndex(c.last)
+		return true, fmt.Sprintf("I challenge %s! Claim a word, which starts with '%s'", c.last, string(c.fragment)), nil
+	case "claim":
+		if c.challenger == "" {
+			return true, "Nobody challenged you", nil
+		}
+		if len(arr) < 2 {
+			return true, "Not correct command, word is expected", nil
+		}
+		return game.claim(arr[1], user)
+	case "skip":
+		return game.penalty(user, fmt.Sprintf("%s gives up.", user))
+	}
+
+	return true, "Don't understand you.", nil
+}
+
+/**
+ * @brief Add letter to the end of fragment
+ * @param[in] letter Normalized letter
+ * @param[in] user User, whose step is now
+ * @return Same values as Continue
+ */
+func (game *Game) addLetter(letter rune, user string) (bool, string, error) {
+	c := &game.classic
+	fragment := string(append(c.fragment, letter))
+
+	if utf8.RuneCountInString(fragment) >= classicMinWord && game.dictionary.CheckWord(fragment) {
+		return game.penalty(user, fmt.Sprintf("%s completed word '%s'.", user, fragment))
+	}
+
+	if !game.dictionary.CheckPrefix(fragment) {
+		return game.penalty(user, fmt.Sprintf("%s made '%s', no word starts with it.", user, fragment))
+	}
+
+	c.fragment = []rune(fragment)
+	c.last = user
+	game.nextStep()
+	return true, fmt.Sprintf("Fragment: %s", strings.ToUpper(fragment)), nil
+}
+
+/**
+ * @brief Check word claimed by challenged user
+ * @param[in] word Word typed by user
+ * @param[in] user Challenged user
+ * @return Same values as Continue
+ */
+func (game *Game) claim(word string, user string) (bool, string, error) {
+	c := &game.classic
+
+	word, err := game.dictionary.Normalize(word)
+	if err == nil && len([]rune(word)) > len(c.fragment) &&
+		strings.HasPrefix(word, string(c.fragment)) && game.dictionary.CheckWord(word) {
+		return game.penalty(c.challenger, fmt.Sprintf("%s claimed word '%s', challenge failed.", user, word))
+	}
+
+	return game.penalty(user, fmt.Sprintf("%s can't claim a word with '%s'.", user, string(c.fragment)))
+}
+
+/**
+ * @brief Give penalty to user and start new round
+ * @param[in] user User, who lost the round
+ * @param[in] reason Message, why user lost the round
+ * @return Same values as Continue
+ *
+ * User, who lost the round, starts the next one
+ */
+func (game *Game) penalty(user string, reason string) (bool, string, error) {
+	c := &game.classic
+	c.penalties[user]++
+	logger.Log.Debugf("Classic game: %s %s", reason, game.penaltyLetters(user))
+
+	c.fragment = nil
+	c.last = ""
+	c.challenger = ""
+	game.stepUser = game.userIndex(user)
+
+	if c.penalties[user] >= utf8.RuneCountInString(classicLoser) {
+		for _, u := range game.users {
+			game.scoreMap[u] = utf8.RuneCountInString(classicLoser) - c.penalties[u]
+		}
+		return game.gameOver(fmt.Sprintf("%s Game over. %s is %s!", reason, user, classicLoser))
+	}
+
+	return true, fmt.Sprintf("%s %s gets '%s'. New round, %s starts", reason, user, game.penaltyLetters(user), user), nil
+}
+
+/**
+ * @brief Pass step to the next user
+ */
+func (game *Game) nextStep() {
+	game.stepUser++
+	if game.stepUser == len(game.users) {
+		game.stepUser = 0
+	}
+}
+
+/**
+ * @brief Index of user in turn order
+ * @param[in] user User's login
+ */
+func (game *Game) userIndex(user string) int {
+	for i, u := range game.users {
+		if u == user {
+			return i
+		}
+	}
+	logger.Log.Warningf("User %s isn't in game", user)
+	return 0
+}
@@ -0,0 +1,458 @@
+/**
+ * @file corr.go
+ * @brief Correspondence games
+ *
+ * Long-running games on area, where every player has hours or days for a move.
+ * Games are stored entirely in database: every command loads the game, applies
+ * the move and saves it back, so players don't need to be online at the same time
+ */
+
+package game
+
+import (
+	// System
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	// Third-party
+
+	// Project
+	"github.com/BaldaGo/balda-go/conf"
+	"github.com/BaldaGo/balda-go/db"
+	"github.com/BaldaGo/balda-go/dict"
+	"github.com/BaldaGo/balda-go/logger"
+)
+
+const ModeCorr = "corr" ///< Correspondence game on area, it is stored in database
+
+var corrLock sync.Mutex ///< Lock of correspondence games, players of one game may be in different connections
+
+/**
+ * @class Corr
+ * @brief Class, provides correspondence games
+ */
+type Corr struct {
+	cfg      conf.GameConf ///< Configuration of games
+	MoveTime time.Duration ///< Default time for a move
+}
+
+/**
+ * @class corrGame
+ * @brief Class, provides correspondence game loaded from database
+ */
+type corrGame struct {
+	game    *Game           ///< Game restored from its state
+	record  *db.CorrGame    ///< State of the game in database
+	players []db.CorrPlayer ///< Players in order of their moves
+}
+
+/**
+ * @brief Create manager of correspondence games
+ * @param[in] cfg Configuration of games
+ */
+func NewCorr(cfg conf.GameConf) *Corr {
+	return &Corr{cfg: cfg, MoveTime: cfg.CorrMoveTime * time.Hour}
+}
+
+/**
+ * @brief Start new correspondence game
+ * @param[in] creator Login of user, who starts the game and moves first
+ * @param[in] invited Logins of other players
+ * @param[in] moveTime Time for a move, default time if it is zero
+ * @return msg Message for user
+ * @return err Error if database failed
+ *
+ * All players are checked before anything is saved into database,
+ * then the game is saved in one transaction
+ */
+func (c *Corr) New(creator string, invited []string, moveTime time.Duration) (string, error) {
+	players := []string{creator}
+	for _, u := range invited {
+		if !contains(players, u) {
+			players = append(players, u)
+		}
+	}
+	if len(players) < 2 || len(players) > c.cfg.NumberUsersPerGame {
+		return fmt.Sprintf("Correspondence game is played by 2-%d players", c.cfg.NumberUsersPerGame), nil
+	}
+	if moveTime <= 0 {
+		moveTime = c.MoveTime
+	}
+
+	unknown, err := db.UnknownUsers(players)
+	if err != nil {
+		return "", err
+	}
+	if len(unknown) > 0 {
+		return fmt.Sprintf("User '%s' is not found", strings.Join(unknown, "', '")), nil
+	}
+
+	g := &Game{AreaSize: c.cfg.AreaSize, StartPolicy: c.cfg.StartWord.Policy, startConf: c.cfg.StartWord}
+	if err := g.setDictionary(c.cfg.Language, c.cfg.Ruleset); err != nil {
+		return "", err
+	}
+	g.randomSeed()
+	word, err := g.chooseStartWord()
+	if err != nil {
+		return "", err
+	}
+
+	square := NewSquare(g.AreaSize, g.dictionary, word)
+	record, err := db.CreateCorrGame(ModeCorr, g.seed, g.Language, g.Ruleset, g.StartPolicy, word,
+		square.letters(), square.usedWords, players, moveTime)
+	if err != nil {
+		return "", err
+	}
+
+	logger.Log.Infof("Correspondence game #%d started by %s", record.ID, creator)
+	return fmt.Sprintf("Correspondence game #%d started: %s. Start word: %s, %s moves first, %s per move",
+		record.ID, strings.Join(players, ", "), word, creator, moveTime), nil
+}
+
+/**
+ * @brief Games, where it is turn of user
+ * @param[in] user User's login
+ * @return msg Inbox of user, empty if user doesn't have to move
+ * @return err Error if database failed
+ */
+func (c *Corr) Inbox(user string) (string, error) {
+	games, err := c.userGames(user)
+	if err != nil {
+		return "", err
+	}
+
+	lines := []string{}
+	for _, cg := range games {
+		if !cg.game.finished && cg.turn() == user {
+			lines = append(lines, cg.summary())
+		}
+	}
+	if len(lines) == 0 {
+		return "", nil
+	}
+	return strings.Join(append([]string{"Your turn in correspondence games:"}, lines...), "\n\r"), nil
+}
+
+/**
+ * @brief Running games of user
+ * @param[in] user User's login
+ * @return msg Message for user
+ * @return err Error if database failed
+ */
+func (c *Corr) List(user string) (string, error) {
+	games, err := c.userGames(user)
+	if err != nil {
+		return "", err
+	}
+
+	lines := []string{}
+	for _, cg := range games {
+		if !cg.game.finished {
+			lines = append(lines, cg.summary())
+		}
+	}
+	if len(lines) == 0 {
+		return "You don't have correspondence games", nil
+	}
+	return strings.Join(lines, "\n\r"), nil
+}
+
+/**
+ * @brief Area, scores and used words of the game
+ * @param[in] id Id of correspondence game
+ * @param[in] user User's login
+ * @return msg Message for user
+ * @return err Error if database failed
+ */
+func (c *Corr) Show(id uint, user string) (string, error) {
+	return c.update(id, user, func(cg *corrGame) (string, error) {
+		lines := []string{cg.summary(), cg.game.square.StrPrintArea(), cg.game.score(), "Words: " + strings.Join(cg.game.square.usedWords, ", ")}
+		return strings.Join(lines, "\n\r"), nil
+	})
+}
+
+/**
+ * @brief Put letter and make word
+ * @param[in] id Id of correspondence game
+ * @param[in] user User's login
+ * @param[in] x Horisontal coordinate of the new letter (column)
+ * @param[in] y Vertical coordinate of the new letter (row)
+ * @param[in] letter New letter
+ * @param[in] word Word made with the new letter
+ * @return msg Message for user
+ * @return err Error if database failed
+ */
+func (c *Corr) Put(id uint, user string, x int, y int, letter string, word string) (string, error) {
+	return c.move(id, user, func(g *Game) (bool, string, error) {
+		if x < 0 || y < 0 || x >= g.AreaSize || y >= g.AreaSize {
+			return true, "Invalid coordinates", nil
+		}
+		sym, err := g.dictionary.NormalizeLetter(letter)
+		if err != nil {
+			return true, fmt.Sprintf("Invalid letter: %s", err.Error()), nil
+		}
+		g.putting.x, g.putting.y, g.putting.sym = x, y, sym
+		return g.word(word)
+	})
+}
+
+/**
+ * @brief Skip move
+ * @param[in] id Id of correspondence game
+ * @param[in] user User's login
+ * @return msg Message for user
+ * @return err Error if database failed
+ */
+func (c *Corr) Skip(id uint, user string) (string, error) {
+	return c.move(id, user, func(g *Game) (bool, string, error) {
+		return g.skip()
+	})
+}
+
+/**
+ * @brief Make move in the game if it is turn of user
+ * @param[in] id Id of correspondence game
+ * @param[in] user User's login
+ * @param[in] f Move, which returns same values as Continue
+ * @return msg Message for user
+ * @return err Error if database failed
+ *
+ * Time for the next move starts, when move is made
+ */
+func (c *Corr) move(id uint, user string, f func(*Game) (bool, string, error)) (string, error) {
+	return c.update(id, user, func(cg *corrGame) (string, error) {
+		if cg.game.finished {
+			return fmt.Sprintf("Game #%d is over", id), nil
+		}
+		if cg.turn() != user {
+			return fmt.Sprintf("Not your step is now, waiting for %s", cg.turn()), nil
+		}
+
+		moves := cg.game.moves
+		_, msg, err := f(cg.game)
+		if err != nil {
+			return "", err
+		}
+		if cg.game.moves != moves {
+			cg.record.Deadline = time.Now().Add(time.Duration(cg.record.MoveTime) * time.Second)
+		}
+		if !cg.game.finished && cg.game.moves != moves {
+			msg = fmt.Sprintf("%s\n\rNow it's turn of %s", msg, cg.turn())
+		}
+		return msg, nil
+	})
+}
+
+/**
+ * @brief Load the game, apply missed deadlines and f, then save the game
+ * @param[in] id Id of correspondence game
+ * @param[in] user User's login, only players can see and change the game
+ * @param[in] f Function, which reads or changes the game
+ * @return msg Message for user
+ * @return err Error if database failed
+ */
+func (c *Corr) update(id uint, user string, f func(*corrGame) (string, error)) (string, error) {
+	corrLock.Lock()
+	defer corrLock.Unlock()
+
+	cg, err := c.load(id)
+	if err != nil && !db.NotFound(err) {
+		return "", err
+	}
+	if err != nil || !contains(cg.game.users, user) {
+		return fmt.Sprintf("Correspondence game #%d is not found", id), nil
+	}
+
+	lines, err := cg.expire()
+	if err != nil {
+		return "", err
+	}
+	msg, err := f(cg)
+	if err != nil {
+		return "", err
+	}
+	if err := cg.save(); err != nil {
+		return "", err
+	}
+	return strings.Join(append(lines, msg), "\n\r"), nil
+}
+
+/**
+ * @brief Load running games of user and apply missed deadlines
+ * @param[in] user User's login
+ * @return games Games from the oldest one
+ * @return err Error if database failed
+ */
+func (c *Corr) userGames(user string) ([]*corrGame, error) {
+	corrLock.Lock()
+	defer corrLock.Unlock()
+
+	ids, err := db.UserCorrGames(user)
+	if err != nil {
+		return nil, err
+	}
+
+	games := []*corrGame{}
+	for _, id := range ids {
+		cg, err := c.load(id)
+		if err != nil {
+			logger.Log.Warning(logger.Trace(err, fmt.Sprintf("Correspondence game #%d can't be loaded", id)).Error())
+			continue
+		}
+		if lines, err := cg.expire(); err != nil {
+			return nil, err
+		} else if len(lines) > 0 {
+			if err := cg.save(); err != nil {
+				return nil, err
+			}
+		}
+		games = append(games, cg)
+	}
+	return games, nil
+}
+
+/**
+ * @brief Restore the game from database
+ * @param[in] id Id of correspondence game
+ * @return cg Loaded game or error if it isn't found
+ */
+func (c *Corr) load(id uint) (*corrGame, error) {
+	record, players, err := db.CorrGameByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	letters := []rune(record.Area)
+	size := int(math.Sqrt(float64(len(letters))))
+	if size*size != len(letters) || len(players) == 0 {
+		return nil, errors.New(fmt.Sprintf("Area of correspondence game #%d is broken", id))
+	}
+
+	g := &Game{
+		AreaSize:      size,
+		Mode:          ModeCorr,
+		dbGameID:      record.GameID,
+		stepUser:      record.Turn,
+		skipped:       record.Skipped,
+		moves:         record.Moves,
+		finished:      record.Finished,
+		onStart:       !record.Finished,
+		scoreMap:      make(map[string]int),
+		ChallengeTime: c.cfg.ChallengeTime * time.Second,
+		Ranked:        record.GameSession.Ranked,
+	}
+	if err := g.setDictionary(record.GameSession.Language, record.GameSession.Ruleset); err != nil {
+		return nil, err
+	}
+	g.SetSeed(record.GameSession.Seed)
+	for _, p := range players {
+		g.users = append(g.users, p.User.Name)
+		g.scoreMap[p.User.Name] = p.Score
+	}
+	g.stepUser %= len(g.users)
+
+	g.square = squareOfLetters(size, g.dictionary, letters)
+	if record.UsedWords != "" {
+		g.square.usedWords = strings.Split(record.UsedWords, ",")
+	}
+
+	return &corrGame{game: g, record: record, players: players}, nil
+}
+
+/**
+ * @brief Save state of the game into database
+ * @return err Error if database failed
+ */
+func (cg *corrGame) save() error {
+	cg.record.Area = cg.game.square.letters()
+	cg.record.UsedWords = strings.Join(cg.game.square.usedWords, ",")
+	cg.record.Turn = cg.game.stepUser
+	cg.record.Skipped = cg.game.skipped
+	cg.record.Moves = cg.game.moves
+	cg.record.Finished = cg.game.finished
+	for i := range cg.players {
+		cg.players[i].Score = cg.game.scoreMap[cg.players[i].User.Name]
+	}
+	return db.SaveCorrGame(cg.record, cg.players)
+}
+
+/**
+ * @brief Skip moves of players, who missed their deadlines
+ * @return lines Messages about skipped moves
+ * @return err Error if database failed
+ */
+func (cg *corrGame) expire() ([]string, error) {
+	lines := []string{}
+	for !cg.game.finished && time.Now().After(cg.record.Deadline) {
+		user := cg.turn()
+		_, msg, err := cg.game.skip()
+		if err != nil {
+			return lines, err
+		}
+		lines = append(lines, fmt.Sprintf("Game #%d: %s missed the deadline and skipped", cg.record.ID, user))
+		if cg.game.finished {
+			lines = append(lines, msg)
+		}
+		cg.record.Deadline = cg.record.Deadline.Add(time.Duration(cg.record.MoveTime) * time.Second)
+	}
+	return lines, nil
+}
+
+/**
+ * @brief Login of user, whose step is now
+ */
+func (cg *corrGame) turn() string {
+	return cg.game.users[cg.game.stepUser]
+}
+
+/**
+ * @brief Players, turn and deadline of the game in one line
+ */
+func (cg *corrGame) summary() string {
+	return fmt.Sprintf("#%d: %s. Turn of %s until %s", cg.record.ID, strings.Join(cg.game.users, ", "),
+		cg.turn(), cg.record.Deadline.Format("2006-01-02 15:04"))
+}
+
+/**
+ * @brief Letters of area row by row
+ */
+func (area Square) letters() string {
+	var letters []rune
+	for i := range area.matrix {
+		letters = append(letters, area.matrix[i]...)
+	}
+	return string(letters)
+}
+
+/**
+ * @brief Constructor of Square from letters row by row
+ * @param[in] size Length side of the gaming area
+ * @param[in] d Dictionary of the game language
+ * @param[in] letters Letters of area, '-' for empty cells
+ */
+func squareOfLetters(size int, d *dict.Dictionary, letters []rune) Square {
+	area := emptySquare(size)
+	area.dictionary = d
+	for i := range area.matrix {
+		copy(area.matrix[i], letters[i*size:(i+1)*size])
+	}
+	return area
+}
+
+/**
+ * @brief Predicate, check if list contains string
+ * @param[in] list List of strings
+ * @param[in] s String to find
+ */
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,204 @@
+/**
+ * @file daily.go
+ * @brief Daily puzzle
+ *
+ * Every day the same puzzle is generated from the date and secret of server,
+ * players try to find the highest-scoring single move on it.
+ * Puzzle isn't taken from file of puzzles, so its answer isn't public
+ */
+
+package game
+
+import (
+	// System
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	// Third-party
+
+	// Project
+	"github.com/BaldaGo/balda-go/conf"
+	"github.com/BaldaGo/balda-go/db"
+	"github.com/BaldaGo/balda-go/logger"
+)
+
+const (
+	dailyAttempts    = 3  ///< Number of attempts of user, the best one counts
+	dailyLeaderboard = 10 ///< Number of users shown in leaderboard
+)
+
+/**
+ * @class Daily
+ * @brief Class, provides daily puzzles
+ */
+type Daily struct {
+	cfg    conf.GameConf ///< Configuration of games
+	mu     sync.Mutex    ///< Lock of puzzle, it is generated by the first user of the day
+	day    string        ///< Date of puzzle
+	puzzle *Puzzle       ///< Puzzle of the day
+}
+
+/**
+ * @brief Create manager of daily puzzles
+ * @param[in] cfg Configuration of games
+ */
+func NewDaily(cfg conf.GameConf) *Daily {
+	return &Daily{cfg: cfg}
+}
+
+/**
+ * @brief Seed of puzzle
+ * @param[in] t Day of puzzle
+ * @param[in] secret Secret of server
+ * @return seed Date as number YYYYMMDD mixed with secret
+ */
+func dailySeed(t time.Time, secret int64) int64 {
+	return int64(t.Year()*10000+int(t.Month())*100+t.Day()) ^ secret
+}
+
+/**
+ * @brief Puzzle of the day and game with current dictionary
+ * @return day Date of puzzle
+ * @return p Puzzle
+ * @return g Game with current dictionary and size of area
+ * @return err Error if puzzle can't be generated
+ *
+ * Puzzle is generated from date by the first user of the day
+ */
+func (d *Daily) today() (string, *Puzzle, *Game, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	g := &Game{AreaSize: d.cfg.AreaSize}
+	if err := g.setDictionary(d.cfg.Language, d.cfg.Ruleset); err != nil {
+		return "", nil, nil, err
+	}
+
+	if day := now.Format("2006-01-02"); d.puzzle == nil || d.day != day {
+		gen, err := NewGenerator(d.cfg, dailySeed(now, d.cfg.DailySecret), MeasureLength)
+		if err != nil {
+			return "", nil, nil, err
+		}
+		puzzles, err := gen.Generate(1, "")
+		if err != nil {
+			return "", nil, nil, err
+		}
+		d.puzzle = &puzzles[0]
+		d.day = day
+		logger.Log.Infof("Daily puzzle %s: %s, the best move gives %d", day, d.puzzle.Difficulty, d.puzzle.Score())
+	}
+
+	return d.day, d.puzzle, g, nil
+}
+
+/**
+ * @brief Predicate, check if puzzle is the puzzle of the day
+ * @param[in] p Puzzle
+ *
+ * Puzzle of the day can't be taken in training, where its answer is shown
+ */
+func (d *Daily) Is(p *Puzzle) bool {
+	_, daily, _, err := d.today()
+	return err == nil && p.same(daily)
+}
+
+/**
+ * @brief Puzzle of the day, result of user and leaderboard
+ * @param[in] user User's login
+ * @return msg Message for user
+ * @return err Error if database failed
+ */
+func (d *Daily) Show(user string) (string, error) {
+	day, p, g, err := d.today()
+	if err != nil {
+		return "", err
+	}
+	result, err := db.DailyResultOf(day, user)
+	if err != nil {
+		return "", err
+	}
+
+	square := p.square(g)
+	lines := []string{
+		fmt.Sprintf("Daily puzzle %s (%s): find the only move, which gives %d", day, p.Difficulty, p.Score()),
+		square.StrPrintArea(),
+		"Words: " + strings.Join(p.Words, ", "),
+	}
+	switch {
+	case result.Attempts == 0:
+		lines = append(lines, fmt.Sprintf("You have %d attempts, type: daily <x> <y> <letter> <word>", dailyAttempts))
+	case result.Score == 0:
+		lines = append(lines, fmt.Sprintf("You haven't found a move yet, attempts %d/%d", result.Attempts, dailyAttempts))
+	default:
+		lines = append(lines, fmt.Sprintf("Your move: '%s' (%d), attempts %d/%d", result.Word, result.Score, result.Attempts, dailyAttempts))
+	}
+
+	board, err := d.leaderboard(day)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(append(lines, board), "\n\r"), nil
+}
+
+/**
+ * @brief Leaderboard of the day, words are hidden to not spoil puzzle
+ * @param[in] day Date of puzzle
+ */
+func (d *Daily) leaderboard(day string) (string, error) {
+	results, err := db.DailyLeaderboard(day, dailyLeaderboard)
+	if err != nil {
+		return "", err
+	}
+	if len(results) == 0 {
+		return "Nobody solved the puzzle yet", nil
+	}
+
+	lines := []string{"Leaderboard:"}
+	for i := range results {
+		lines = append(lines, fmt.Sprintf("%d. %s : %d", i+1, results[i].User.Name, results[i].Score))
+	}
+	return strings.Join(lines, "\n\r"), nil
+}
+
+/**
+ * @brief Check move of user in puzzle of the day
+ * @param[in] user User's login
+ * @param[in] x Horisontal coordinate of the new letter (column)
+ * @param[in] y Vertical coordinate of the new letter (row)
+ * @param[in] letter New letter
+ * @param[in] word Word made with the new letter
+ * @return msg Message for user
+ * @return err Error if database failed
+ *
+ * Wrong move takes an attempt too, limit of attempts is checked while result is saved
+ */
+func (d *Daily) Submit(user string, x int, y int, letter string, word string) (string, error) {
+	day, p, g, err := d.today()
+	if err != nil {
+		return "", err
+	}
+
+	score, word, err := p.check(g, x, y, letter, word)
+	if err != nil {
+		return err.Error(), nil
+	}
+	result, saved, err := db.SaveDailyResult(day, user, word, uint(score), dailyAttempts)
+	if err != nil {
+		return "", err
+	}
+	if !saved {
+		return fmt.Sprintf("You have used all %d attempts today, your move: '%s' (%d)", dailyAttempts, result.Word, result.Score), nil
+	}
+
+	left := dailyAttempts - int(result.Attempts)
+	switch {
+	case score == 0:
+		return fmt.Sprintf("You can't make word '%s' there, attempts left: %d", word, left), nil
+	case score == p.Score():
+		return fmt.Sprintf("Excellent! '%s' gives %d, it is the best move", word, score), nil
+	}
+	return fmt.Sprintf("'%s' gives %d, the best move gives %d. Attempts left: %d", word, score, p.Score(), left), nil
+}
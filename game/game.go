@@ -10,8 +10,12 @@ import (
 	// System
 	"errors"
 	"fmt"
+	"math/rand"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 	"unicode/utf8"
 
 	// Third-party
@@ -20,10 +24,13 @@ import (
 	// Project
 	"github.com/BaldaGo/balda-go/conf"
 	"github.com/BaldaGo/balda-go/db"
+	"github.com/BaldaGo/balda-go/dict"
 	"github.com/BaldaGo/balda-go/logger"
 )
 
 const databaseError string = "DATABASE_ERROR"
+const versusLimit = 5 ///< Default number of the last games in head-to-head statistics
+const formLength = 10 ///< Number of the last games in form of profile
 
 /**
  * @class Game
@@ -41,6 +48,37 @@ type Game struct {
 	onPut           bool
 	AreaSize        int
 	MaxUsersPerGame int
+	Language        string             ///< Name of the game dictionary
+	Ruleset         string             ///< Name of ruleset which filters words of dictionary
+	dictionary      *dict.Dictionary   ///< Dictionary of the game language filtered by ruleset
+	StartPolicy     string             ///< Policy of start word selection
+	startConf       conf.StartWordConf ///< Parameters of start word policies
+	startWord       string             ///< Start word chosen by admin for the next game
+	seed            int64              ///< Seed of session random generator
+	rand            *rand.Rand         ///< Random generator of the session
+	moves           uint               ///< Number of moves made in the game
+	TurnOrder       string             ///< Policy of turn order
+	joined          []string           ///< Users in order they joined the session
+	rounds          int                ///< Number of started games, turn order is rotated by it
+	finished        bool               ///< Flag if game is over and users vote for rematch
+	rematch         map[string]bool    ///< Users, who voted for rematch
+	Mode            string             ///< Mode of the game (grid, classic)
+	classic         classic            ///< State of classic game
+	hunt            *hunt              ///< State of hunt game
+	HuntTime        time.Duration      ///< Duration of hunt game
+	Notifier        Notifier           ///< Callback to send messages without message of user
+	Teams           bool               ///< Flag if game is played by two teams
+	team            map[string]string  ///< Team by user
+	last            *lastMove          ///< Last move, which can be challenged
+	challenge       *challenge         ///< Running challenge of the last move
+	ChallengeTime   time.Duration      ///< Time to challenge word and to vote
+	Ranked          bool               ///< Flag if game changes statistics of users, false for practice
+	bots            []string           ///< Bots of the game, they are in users too
+	opening         string             ///< Moves of bots made when game started
+	reservation     *reservation       ///< Reservation of session for tournament game
+	OnFinish        FinishHandler      ///< Callback, which is called when game is over
+	mu              sync.Mutex         ///< Lock of game state, it is changed by users and by timers
+	private         []privateReply     ///< Replies to single users, they are sent after lock is released
 	meth            methods
 }
 
@@ -54,18 +92,32 @@ type Put struct {
 }
 
 type methods struct {
-	area  func() string                `description:"Shows game area"`
-	words func() string                `description:"Shows used words"`
-	step  func() string                `description:"Shows name of user who's step is now"`
-	score func() string                `description:"Shows score of every user in game"`
-	help  func() string                `description:"Help for you"`
-	skip  func() (bool, string, error) `description:"Command to skip (if your step is now)"`
-	put   func() string                `description:"Command to put letter and tell word (if your step is now)"`
+	area      func() string                                `description:"Shows game area"`
+	words     func() string                                `description:"Shows used words"`
+	step      func() string                                `description:"Shows name of user who's step is now"`
+	order     func() string                                `description:"Shows turn order of the game"`
+	score     func() string                                `description:"Shows score of every user in game"`
+	help      func() string                                `description:"Help for you"`
+	skip      func() (bool, string, error)                 `description:"Command to skip (if your step is now)"`
+	put       func() string                                `description:"Command to put letter and tell word (if your step is now)"`
+	lang      func([]string) (bool, string, error)         `description:"Shows or chooses language before game starts. Parameters: language"`
+	rules     func([]string) (bool, string, error)         `description:"Shows or chooses ruleset before game starts. Parameters: ruleset"`
+	mode      func([]string) (bool, string, error)         `description:"Shows or chooses mode before game starts. Parameters: mode(grid, classic, hunt)"`
+	teams     func([]string) (bool, string, error)         `description:"Shows or switches team play before game starts. Parameters: on|off"`
+	practice  func([]string) (bool, string, error)         `description:"Shows or switches unranked practice before game starts. Parameters: on|off"`
+	define    func(string) string                          `description:"Shows short definition of word. Parameters: word"`
+	replay    func(int) (bool, string, error)              `description:"Replays finished game by its seed and moves. Parameters: game id"`
+	challenge func(string) (bool, string, error)           `description:"Challenges the word of the last move, players vote if it stands"`
+	vote      func([]string, string) (bool, string, error) `description:"Votes in running challenge. Parameters: keep|undo"`
 
-	stat_topusers     func(string, int, int) (bool, string, error) `description:"Shows top of users. Parameters: mode(score, games, wins), limit"`
-	stat_topwords     func(int, int) (bool, string, error)         `description:"Shows top of words. Parameters: limit"`
-	stat_wordtopusers func(string, int, int) (bool, string, error) `description:"Shows top of users used this word. Parameters: word, limit"`
-	stat_user         func(string, int, int) (bool, string, error) `description:"Shows top of users. Parameters: username, limit"`
+	stat_topusers     func(string, int, int) (bool, string, error)    `description:"Shows top of users. Parameters: mode(score, games, wins, rating), limit, period(all, today, week, month, season)"`
+	stat_topwords     func(int, int) (bool, string, error)            `description:"Shows top of words. Parameters: limit"`
+	stat_wordtopusers func(string, int, int) (bool, string, error)    `description:"Shows top of users used this word. Parameters: word, limit"`
+	stat_user         func(string, int, int) (bool, string, error)    `description:"Shows top of users. Parameters: username, limit"`
+	stat_rating       func(string, int) (bool, string, error)         `description:"Shows rating history of user. Parameters: username, limit"`
+	stat_seasons      func() (bool, string, error)                    `description:"Shows seasons of leaderboards"`
+	stat_vs           func(string, string, int) (bool, string, error) `description:"Shows head-to-head statistics of two users. Parameters: username, username, limit of the last games"`
+	profile           func(string) (bool, string, error)              `description:"Shows profile of user: win rate, words, form and streak. Parameters: username"`
 }
 
 /**
@@ -73,7 +125,10 @@ type methods struct {
  * @return game Pointer to the created Game object
  */
 func NewGame(cfg conf.GameConf) (*Game, error) {
-	g := &Game{square: NewSquare(cfg.AreaSize)}
+	g := &Game{}
+	if err := g.setDictionary(cfg.Language, cfg.Ruleset); err != nil {
+		return nil, err
+	}
 
 	res, err := db.StartGame()
 	if err != nil {
@@ -88,19 +143,42 @@ func NewGame(cfg conf.GameConf) (*Game, error) {
 	g.AreaSize = cfg.AreaSize
 	g.MaxUsersPerGame = cfg.NumberUsersPerGame
 	g.scoreMap = make(map[string]int)
+	g.StartPolicy = cfg.StartWord.Policy
+	g.startConf = cfg.StartWord
+	g.TurnOrder = cfg.TurnOrder
+	g.Mode = cfg.Mode
+	g.HuntTime = cfg.HuntTime * time.Second
+	g.Teams = cfg.Teams
+	g.Ranked = true
+	g.ChallengeTime = cfg.ChallengeTime * time.Second
+	g.randomSeed()
 
 	g.meth.area = g.area
 	g.meth.words = g.words
 	g.meth.step = g.step
+	g.meth.order = g.Order
 	g.meth.score = g.score
 	g.meth.help = g.help
 	g.meth.skip = g.skip
 	g.meth.put = g.put
+	g.meth.lang = g.lang
+	g.meth.rules = g.rules
+	g.meth.mode = g.mode
+	g.meth.teams = g.teams
+	g.meth.practice = g.practice
+	g.meth.define = g.define
+	g.meth.replay = g.replay
+	g.meth.challenge = g.startChallenge
+	g.meth.vote = g.vote
 
 	g.meth.stat_topusers = g.GetTopUsersByMode
 	g.meth.stat_topwords = g.GetTopWords
 	g.meth.stat_wordtopusers = g.GetWordTopUsers
 	g.meth.stat_user = g.GetUserAllGamesStat
+	g.meth.stat_rating = g.GetUserRatingHistory
+	g.meth.stat_seasons = g.GetSeasons
+	g.meth.stat_vs = g.GetVersusStat
+	g.meth.profile = g.GetProfile
 
 	g.putting.funcMap = make(map[string]interface{})
 	g.putting.funcMap["coordX"] = g.coordX
@@ -111,19 +189,77 @@ func NewGame(cfg conf.GameConf) (*Game, error) {
 	return g, nil
 }
 
+/**
+ * @brief Handle message of user and make moves of bots after it
+ * @param[in] str Message of user
+ * @param[in] user User's login
+ * @return play False if game is over
+ * @return response Message for users
+ * @return err Error if it occured
+ *
+ * Messages of users are handled one by one under lock of the game,
+ * replies to single users are sent by Notifier after lock is released
+ */
 func (game *Game) Continue(str string, user string) (bool, string, error) {
+	game.mu.Lock()
+	play, response, err := game.handle(str, user)
+	private := game.private
+	game.private = nil
+	game.mu.Unlock()
+
+	for _, r := range private {
+		game.Notifier([]string{r.user}, r.msg, false)
+	}
+	return play, response, err
+}
+
+/**
+ * @brief Handle message of user and make moves of bots after it, lock must be taken
+ * @param[in] str Message of user
+ * @param[in] user User's login
+ * @return Same values as Continue
+ */
+func (game *Game) handle(str string, user string) (bool, string, error) {
+	play, response, err := game.command(str, user)
+	if !play || err != nil || !game.botTurn() {
+		return play, response, err
+	}
+
+	play, moves, err := game.playBots()
+	if response != "" {
+		moves = strings.Join([]string{response, moves}, "\n\r")
+	}
+	return play, moves, err
+}
+
+/**
+ * @brief Handle message of user
+ * @param[in] str Message of user
+ * @param[in] user User's login
+ * @return Same values as Continue
+ */
+func (game *Game) command(str string, user string) (bool, string, error) {
 	arr := strings.Split(str, " ")
 	if arr[0] == "stat_topusers" {
+		if len(arr) < 3 {
+			return true, "Not correct command, use: stat_topusers <score|games|wins|rating> <limit> [period]", nil
+		}
 		n, err := strconv.Atoi(arr[2])
 		if err != nil {
-			return true, "Not correct command, not integer in limit", err
+			return true, "Not correct command, not integer in limit", nil
+		}
+		if arr[1] != "score" && arr[1] != "games" && arr[1] != "wins" && arr[1] != "rating" {
+			return true, "Not correct command, bad mode. You must use one of: score, games, wins, rating.", nil
 		}
-		if arr[1] != "score" && arr[1] != "games" && arr[1] != "wins" {
-			return true, "Not correct command, bad mode. You must use one of: score, games, wins.", err
+		if len(arr) > 3 && arr[3] != PeriodAll {
+			return game.GetTopUsersByPeriod(arr[1], n, arr[3])
 		}
 		return game.meth.stat_topusers(arr[1], n, 0)
 	}
 	if arr[0] == "stat_topwords" {
+		if len(arr) < 2 {
+			return true, "Not correct command, use: stat_topwords <limit>", nil
+		}
 		n, err := strconv.Atoi(arr[1])
 		if err != nil {
 			return true, "Not correct command, not integer in limit", err
@@ -131,6 +267,9 @@ func (game *Game) Continue(str string, user string) (bool, string, error) {
 		return game.meth.stat_topwords(n, 0)
 	}
 	if arr[0] == "stat_wordtopusers" {
+		if len(arr) < 3 {
+			return true, "Not correct command, use: stat_wordtopusers <word> <limit>", nil
+		}
 		n, err := strconv.Atoi(arr[2])
 		if err != nil {
 			return true, "Not correct command, not integer in limit", err
@@ -138,13 +277,88 @@ func (game *Game) Continue(str string, user string) (bool, string, error) {
 		return game.meth.stat_wordtopusers(arr[1], n, 0)
 	}
 	if arr[0] == "stat_user" {
+		if len(arr) < 3 {
+			return true, "Not correct command, use: stat_user <username> <limit>", nil
+		}
 		n, err := strconv.Atoi(arr[2])
 		if err != nil {
 			return true, "Not correct command, not integer in limit", err
 		}
 		return game.meth.stat_user(arr[1], n, 0)
 	}
+	if arr[0] == "stat_vs" {
+		if len(arr) < 3 {
+			return true, "Not correct command, two usernames are expected", nil
+		}
+		n := versusLimit
+		if len(arr) > 3 {
+			var err error
+			if n, err = strconv.Atoi(arr[3]); err != nil {
+				return true, "Not correct command, not integer in limit", nil
+			}
+		}
+		return game.meth.stat_vs(arr[1], arr[2], n)
+	}
+	if arr[0] == "stat_seasons" {
+		return game.meth.stat_seasons()
+	}
+	if arr[0] == "profile" {
+		if len(arr) < 2 {
+			return true, "Not correct command, username is expected", nil
+		}
+		return game.meth.profile(arr[1])
+	}
+	if arr[0] == "stat_rating" {
+		if len(arr) < 3 {
+			return true, "Not correct command, username and limit are expected", nil
+		}
+		n, err := strconv.Atoi(arr[2])
+		if err != nil {
+			return true, "Not correct command, not integer in limit", nil
+		}
+		return game.meth.stat_rating(arr[1], n)
+	}
+	if game.reservation != nil && len(arr) > 1 && arr[1] != "" && contains(setupCommands, arr[0]) {
+		return true, "Rules of tournament game can't be changed", nil
+	}
+	if arr[0] == "lang" {
+		return game.meth.lang(arr[1:])
+	}
+	if arr[0] == "rules" {
+		return game.meth.rules(arr[1:])
+	}
+	if arr[0] == "mode" {
+		return game.meth.mode(arr[1:])
+	}
+	if arr[0] == "teams" {
+		return game.meth.teams(arr[1:])
+	}
+	if arr[0] == "practice" {
+		return game.meth.practice(arr[1:])
+	}
+	if arr[0] == "define" {
+		if len(arr) < 2 {
+			return true, "Not correct command, word is expected", nil
+		}
+		return true, game.meth.define(arr[1]), nil
+	}
+	if arr[0] == "replay" {
+		if len(arr) < 2 {
+			return true, "Not correct command, game id is expected", nil
+		}
+		n, err := strconv.Atoi(arr[1])
+		if err != nil || n <= 0 {
+			return true, "Not correct command, not positive integer in game id", nil
+		}
+		return game.meth.replay(n)
+	}
+	if str == "words" && len(game.square.usedWords) > 0 {
+		return true, game.words(), nil
+	}
 
+	if game.finished {
+		return true, "Game is over. Type 'rematch' to play again or 'leave' to return to lobby", nil
+	}
 	if !game.onStart {
 		return true, "Game didn't start", nil
 	}
@@ -152,11 +366,17 @@ func (game *Game) Continue(str string, user string) (bool, string, error) {
 		return true, game.area(), nil
 	}
 	if str == "words" {
+		if game.Mode == ModeHunt {
+			return true, game.huntWords(user), nil
+		}
 		return true, game.words(), nil
 	}
 	if str == "step" {
 		return true, game.step(), nil
 	}
+	if str == "order" {
+		return true, game.Order(), nil
+	}
 	if str == "score" {
 		return true, game.score(), nil
 	}
@@ -164,12 +384,30 @@ func (game *Game) Continue(str string, user string) (bool, string, error) {
 		return true, game.help(), nil
 	}
 
+	if game.Mode == ModeHunt {
+		return game.huntWord(str, user)
+	}
+	if game.Mode == ModeGrid {
+		if arr[0] == "challenge" {
+			return game.meth.challenge(user)
+		}
+		if arr[0] == "vote" {
+			return game.meth.vote(arr[1:], user)
+		}
+		if game.challenge != nil {
+			return game.waitChallenge()
+		}
+	}
+
 	if game.stepUser >= len(game.users) {
 		game.stepUser = game.stepUser % len(game.users)
 	}
 	if user != game.users[game.stepUser] {
 		return true, "Not your step is now or not correct command.", nil
 	}
+	if game.Mode == ModeClassic {
+		return game.classicMove(arr, user)
+	}
 	if str == "skip" {
 		return game.skip()
 	}
@@ -183,11 +421,37 @@ func (game *Game) Continue(str string, user string) (bool, string, error) {
 	return true, "Don't understand you.", nil
 }
 
+/**
+ * @brief Predicate, check if all places of the game are taken
+ */
+func (game *Game) Full() bool {
+	return len(game.users) >= game.MaxUsersPerGame
+}
+
+/**
+ * @brief Add user to the game
+ * @param[in] login User's login
+ * @return err Error if game is full, started or reserved for other users
+ */
 func (game *Game) AddUser(login string) error {
-	if game.onStart || len(game.users) >= game.MaxUsersPerGame {
+	game.mu.Lock()
+	defer game.mu.Unlock()
+	return game.addUser(login)
+}
+
+/**
+ * @brief Add user to the game, lock must be taken
+ * @param[in] login User's login
+ */
+func (game *Game) addUser(login string) error {
+	if game.onStart || game.Full() {
 		return errors.New("Can't add user to game")
 	}
+	if game.reservation != nil && !contains(game.reservation.players, login) {
+		return errors.New("Session is reserved for tournament game")
+	}
 	game.users = append(game.users, login)
+	game.joined = append(game.joined, login)
 	game.scoreMap[login] = 0
 
 	if _, err := db.NewUserInSession(login, game.dbGameID); err != nil {
@@ -198,27 +462,123 @@ func (game *Game) AddUser(login string) error {
 }
 
 func (game *Game) StartGame() error {
+	game.mu.Lock()
+	defer game.mu.Unlock()
+
+	game.refreshDictionary()
+	policy, word := "", ""
+	if game.Mode == ModeGrid {
+		policy = game.StartPolicy
+		if game.startWord != "" {
+			policy = StartAdmin
+		}
+		var err error
+		if word, err = game.chooseStartWord(); err != nil {
+			return err
+		}
+		game.square = NewSquare(game.AreaSize, game.dictionary, word)
+	}
+	game.moves = 0
+	game.last = nil
+	game.challenge = nil
+
+	if err := game.arrangeUsers(); err != nil {
+		return err
+	}
+	if err := game.assignTeams(); err != nil {
+		return err
+	}
+	switch game.Mode {
+	case ModeClassic:
+		game.startClassic()
+	case ModeHunt:
+		game.startHunt()
+	}
+
+	if err := db.SetupGame(game.dbGameID, game.Mode, game.seed, game.Language, game.Ruleset, policy, word, game.users, game.Ranked); err != nil {
+		return err
+	}
+	if game.Mode == ModeHunt {
+		game.runHunt()
+	}
 	game.onStart = true
 
-	return nil
+	_, opening, err := game.playBots()
+	game.opening = opening
+	return err
 }
 
-func (game *Game) FinishGame(winner string) (string, error) {
+func (game *Game) FinishGame(result db.GameResult) error {
 	game.onStart = false
-	err := db.GameOver(game.scoreMap, game.dbGameID, winner)
-	if err != nil {
-		return databaseError, err
-	}
-
-	return winner, nil
+	game.finished = true
+	game.rematch = make(map[string]bool)
+	game.SetSeed(game.rand.Int63())
+	return db.GameOver(game.dbGameID, result)
 }
 
 func (game *Game) area() string {
+	switch game.Mode {
+	case ModeClassic:
+		return game.fragment()
+	case ModeHunt:
+		return game.huntArea()
+	}
 	return game.square.StrPrintArea()
 }
 
+/**
+ * @brief Message for users, when game starts
+ */
+func (game *Game) Announce() string {
+	if game.Mode == ModeHunt {
+		return fmt.Sprintf("Find words of %d and more letters in %s! Type them one per line.\n\r%s",
+			huntMinWord, game.HuntTime, game.square.StrPrintArea())
+	}
+	msg := fmt.Sprintf("Turn order: %s", game.Order())
+	if game.opening != "" {
+		msg = strings.Join([]string{msg, game.opening}, "\n\r")
+		game.opening = ""
+	}
+	return msg
+}
+
+/**
+ * @brief Used words, words with definitions are marked
+ */
 func (game *Game) words() string {
-	return game.square.StrPrintUsedWords()
+	lines := []string{}
+	marked := false
+	for _, w := range game.square.usedWords {
+		if game.dictionary.HasDefinition(w) {
+			w += " *"
+			marked = true
+		}
+		lines = append(lines, w)
+	}
+	if marked {
+		lines = append(lines, "* - type 'define <word>' to read definition")
+	}
+	return strings.Join(lines, "\n\r")
+}
+
+/**
+ * @brief Short definition of word from definitions file of the game language
+ * @param[in] word Word typed by user
+ */
+func (game *Game) define(word string) string {
+	word, err := game.dictionary.Normalize(word)
+	if err != nil {
+		return fmt.Sprintf("Invalid word: %s", err.Error())
+	}
+
+	lemma, text, ok := game.dictionary.Define(word)
+	if !ok {
+		return fmt.Sprintf("Definition of '%s' is not found", word)
+	}
+	if lemma != word {
+		return fmt.Sprintf("%s (%s): %s", word, lemma, text)
+	}
+	return fmt.Sprintf("%s: %s", word, text)
 }
 
 func (game *Game) step() string {
@@ -229,6 +589,9 @@ func (game *Game) step() string {
 }
 
 func (game *Game) score() string {
+	if game.Teams {
+		return game.teamScore()
+	}
 	str := ""
 	for us, sc := range game.scoreMap {
 		str = strings.Join([]string{str, us, " : ", strconv.Itoa(sc), "\n\r"}, "")
@@ -237,6 +600,76 @@ func (game *Game) score() string {
 	return str
 }
 
+/**
+ * @brief Final standings of the game
+ * @return str Users sorted by score, one per line
+ */
+func (game *Game) standings() string {
+	users := make([]string, len(game.users))
+	copy(users, game.users)
+	sort.SliceStable(users, func(i, j int) bool {
+		return game.scoreMap[users[i]] > game.scoreMap[users[j]]
+	})
+
+	lines := []string{"Final standings:"}
+	for i, us := range users {
+		lines = append(lines, fmt.Sprintf("%d. %s : %d", i+1, us, game.scoreMap[us]))
+	}
+	return strings.Join(lines, "\n\r")
+}
+
+/**
+ * @brief Winner of the game
+ * @return winner Login of the user with the highest score or empty string if it is a draw
+ */
+func (game *Game) winner() string {
+	winner := ""
+	hs := -1
+	for us, sc := range game.scoreMap {
+		if sc > hs {
+			hs = sc
+			winner = us
+		} else if sc == hs {
+			winner = ""
+		}
+	}
+	return winner
+}
+
+/**
+ * @brief Finish the game and announce final standings
+ * @param[in] reason Message, why game is over
+ * @return Same values as Continue
+ */
+func (game *Game) gameOver(reason string) (bool, string, error) {
+	res := game.result()
+	if err := game.FinishGame(res); err != nil {
+		logger.Log.Critical(err.Error())
+		return false, databaseError, err
+	}
+
+	lines := []string{reason}
+	if game.OnFinish != nil {
+		if note := game.OnFinish(game.dbGameID, res); note != "" {
+			lines = append(lines, note)
+		}
+	}
+	if game.Teams {
+		lines = append(lines, game.teamStandings())
+	}
+	lines = append(lines, game.standings())
+
+	switch {
+	case res.WinnerTeam != "":
+		lines = append(lines, fmt.Sprintf("Our winners: team %s (%s)", res.WinnerTeam, strings.Join(res.Winners, ", ")))
+	case len(res.Winners) > 0:
+		lines = append(lines, fmt.Sprintf("Our winner: %s", res.Winners[0]))
+	default:
+		lines = append(lines, "No winner.")
+	}
+	return false, strings.Join(lines, "\n\r"), nil
+}
+
 func (game *Game) help() string {
 	help_messeges := []string{"Game balda"}
 	m := structs.New(&methods{})
@@ -247,10 +680,15 @@ func (game *Game) help() string {
 }
 
 func (game *Game) skip() (bool, string, error) {
+	if err := game.logMove(game.users[game.stepUser], db.MoveSkip, -1, -1, "", ""); err != nil {
+		logger.Log.Critical(err.Error())
+		return false, databaseError, err
+	}
+
+	game.last = nil
 	game.skipped++
 	if game.skipped == len(game.users) {
-		game.FinishGame("")
-		return false, "Game over. No winner. All users skipped.", nil
+		return game.gameOver("Game over. All users skipped.")
 	}
 	game.stepUser++
 	if game.stepUser == len(game.users) {
@@ -259,7 +697,87 @@ func (game *Game) skip() (bool, string, error) {
 	return true, "You skipped", nil
 }
 
+/**
+ * @brief Show or choose language of the game
+ * @param[in] args Name of language, if it is empty, shows available languages
+ * @return Same values as Continue
+ *
+ * Language can be changed only before game starts
+ */
+func (game *Game) lang(args []string) (bool, string, error) {
+	if len(args) == 0 || args[0] == "" {
+		return true, fmt.Sprintf("Language: %s. Available: %s", game.Language, strings.Join(dict.Names(), ", ")), nil
+	}
+	if game.onStart {
+		return true, "Game already started, language can't be changed", nil
+	}
+
+	if err := game.setDictionary(args[0], game.Ruleset); err != nil {
+		return true, fmt.Sprintf("%s. Available: %s", err.Error(), strings.Join(dict.Names(), ", ")), nil
+	}
+	return true, fmt.Sprintf("Language of the game changed to %s", game.Language), nil
+}
+
+/**
+ * @brief Show or choose ruleset of the game
+ * @param[in] args Name of ruleset, if it is empty, shows available rulesets
+ * @return Same values as Continue
+ *
+ * Ruleset can be changed only before game starts
+ */
+func (game *Game) rules(args []string) (bool, string, error) {
+	if len(args) == 0 || args[0] == "" {
+		return true, fmt.Sprintf("Ruleset: %s. Available: %s", game.Ruleset, strings.Join(game.dictionary.Rulesets(), ", ")), nil
+	}
+	if game.onStart {
+		return true, "Game already started, ruleset can't be changed", nil
+	}
+
+	if err := game.setDictionary(game.Language, args[0]); err != nil {
+		return true, fmt.Sprintf("%s. Available: %s", err.Error(), strings.Join(game.dictionary.Rulesets(), ", ")), nil
+	}
+	return true, fmt.Sprintf("Ruleset of the game changed to %s", game.Ruleset), nil
+}
+
+/**
+ * @brief Choose dictionary of the game
+ * @param[in] language Name of dictionary
+ * @param[in] ruleset Name of ruleset which filters words of dictionary
+ * @return err Error if dictionary or ruleset is unknown
+ */
+func (game *Game) setDictionary(language string, ruleset string) error {
+	d, ok := dict.Get(language)
+	if !ok {
+		return errors.New(fmt.Sprintf("Unknown language '%s'", language))
+	}
+
+	d, ok = d.WithRuleset(ruleset)
+	if !ok {
+		return errors.New(fmt.Sprintf("Unknown ruleset '%s'", ruleset))
+	}
+
+	game.Language = language
+	game.Ruleset = ruleset
+	game.dictionary = d
+	return nil
+}
+
+/**
+ * @brief Take the current version of the game dictionary
+ *
+ * Dictionary can be changed by admins at runtime,
+ * so it is taken before every move and stays the same until move ends
+ */
+func (game *Game) refreshDictionary() {
+	if err := game.setDictionary(game.Language, game.Ruleset); err != nil {
+		logger.Log.Warning(logger.Trace(err, "Dictionary of the game is not refreshed").Error())
+		return
+	}
+	game.square.dictionary = game.dictionary
+}
+
 func (game *Game) put() string {
+	game.refreshDictionary()
 	game.onPut = true
 	game.putting.state = "coordX"
 	return "Entering X coordinate"
@@ -286,45 +804,62 @@ func (game *Game) coordY(str string) (bool, string, error) {
 }
 
 func (game *Game) letter(str string) (bool, string, error) {
-	if utf8.RuneCountInString(str) != 1 {
-		return true, "Invalid. Try again.", nil
+	sym, err := game.dictionary.NormalizeLetter(str)
+	if err != nil {
+		return true, fmt.Sprintf("Invalid letter: %s. Try again.", err.Error()), nil
 	}
-	game.putting.sym = []rune(str)[0]
+	game.putting.sym = sym
 	game.putting.state = "word"
 	return true, "Entering word", nil
 }
 
 func (game *Game) word(str string) (bool, string, error) {
+	str, err := game.dictionary.Normalize(str)
+	if err != nil {
+		return true, fmt.Sprintf("Invalid word: %s. Try again.", err.Error()), nil
+	}
 	game.putting.word = str
 	game.onPut = false
 
+	if !game.dictionary.CheckWord(str) {
+		return true, fmt.Sprintf("Word '%s' is not in dictionary. Try again or propose it: propose %s", str, str), nil
+	}
+
 	ok := game.square.CheckWord(game.putting.y, game.putting.x, game.putting.sym, []rune(game.putting.word))
 	if ok {
 		sc := utf8.RuneCountInString(game.putting.word)
 		nowPlayer := game.users[game.stepUser]
 		game.scoreMap[nowPlayer] += sc
 
-		if _, err := db.AddWord(nowPlayer, str); err != nil {
+		if game.Ranked {
+			if _, err := db.AddWord(nowPlayer, str, game.Language); err != nil {
+				logger.Log.Critical(err.Error())
+				return false, databaseError, err
+			}
+		}
+		if err := game.logMove(nowPlayer, db.MovePut, game.putting.x, game.putting.y, string(game.putting.sym), str); err != nil {
 			logger.Log.Critical(err.Error())
 			return false, databaseError, err
 		}
 
-		if game.square.IsFull() {
-			winner := ""
-			hs := 0
-			for us, sc := range game.scoreMap {
-				if sc > hs {
-					hs = sc
-					winner = us
-				}
-				if sc == hs {
-					winner = ""
-				}
-			}
+		skipped := game.skipped
+		game.skipped = 0
 
-			game.FinishGame(winner)
-			return false, strings.Join([]string{"Game over.", game.score(), "Our winner:", winner}, "\n\r"), nil
+		if game.square.IsFull() {
+			return game.gameOver("Game over. Area is full.")
 		}
+		if !game.square.HasMove() {
+			return game.gameOver("Game over. No moves left.")
+		}
+		game.rememberMove(lastMove{
+			user:    nowPlayer,
+			x:       game.putting.x,
+			y:       game.putting.y,
+			letter:  string(game.putting.sym),
+			word:    str,
+			score:   sc,
+			skipped: skipped,
+		})
 
 		game.stepUser++
 		if game.stepUser == len(game.users) {
@@ -345,11 +880,12 @@ func (game *Game) GetTopUsersByMode(mode string, limit int, offset int) (bool, s
 	var prepare []string
 	for i := range res {
 		prepare = append(prepare,
-			fmt.Sprintf("Login: %s, Scores: %d, Games: %d, Wins: %d",
+			fmt.Sprintf("Login: %s, Scores: %d, Games: %d, Wins: %d, Rating: %.0f",
 				res[i].Name,
 				res[i].Scores,
 				res[i].Games,
-				res[i].Wins))
+				res[i].Wins,
+				res[i].Rating))
 	}
 
 	pretty := strings.Join(prepare, "\n\r")
@@ -409,13 +945,146 @@ func (game *Game) GetUserAllGamesStat(username string, limit int, offset int) (b
 					value.Users[j].Scores))
 		}
 		anotherUsers := strings.Join(usersLocalList, "\n\r")
+		winner := value.Winner
+		if value.WinnerTeam != "" {
+			var members []string
+			for name, team := range value.Teams {
+				if team == value.WinnerTeam {
+					members = append(members, name)
+				}
+			}
+			sort.Strings(members)
+			winner = fmt.Sprintf("team %s (%s)", value.WinnerTeam, strings.Join(members, ", "))
+		}
+		practice := ""
+		if !value.Ranked {
+			practice = " (practice)"
+		}
 		prepare = append(prepare,
-			fmt.Sprintf("GameID: %d, Winner: %s \n\rAnother players: \n\r%s",
+			fmt.Sprintf("GameID: %d%s, Winner: %s \n\rAnother players: \n\r%s",
 				key,
-				value.Winner,
+				practice,
+				winner,
 				anotherUsers))
 	}
 
 	pretty := strings.Join(prepare, "\n\r\n\r")
 	return true, pretty, nil
 }
+
+func (game *Game) GetUserRatingHistory(username string, limit int) (bool, string, error) {
+	res, err := db.UserRatingHistory(username, uint(limit))
+	if db.NotFound(err) {
+		return true, fmt.Sprintf("User '%s' is not found", username), nil
+	}
+	if err != nil {
+		return true, databaseError, err
+	}
+	if len(res) == 0 {
+		return true, fmt.Sprintf("%s hasn't played ranked games yet", username), nil
+	}
+
+	var prepare []string
+	for i := range res {
+		prepare = append(prepare,
+			fmt.Sprintf("GameID: %d, Rating: %.0f -> %.0f (%+.0f)",
+				res[i].GameID,
+				res[i].Before,
+				res[i].After,
+				res[i].After-res[i].Before))
+	}
+
+	pretty := strings.Join(prepare, "\n\r")
+	return true, pretty, nil
+}
+
+func (game *Game) GetVersusStat(username1 string, username2 string, limit int) (bool, string, error) {
+	if username1 == username2 {
+		return true, "Not correct command, usernames must be different", nil
+	}
+	stat, meetings, err := db.Versus(username1, username2, uint(limit))
+	if db.NotFound(err) {
+		return true, fmt.Sprintf("User '%s' or '%s' is not found", username1, username2), nil
+	}
+	if err != nil {
+		return true, databaseError, err
+	}
+	if stat.Meetings == 0 {
+		return true, fmt.Sprintf("%s and %s haven't played with each other", username1, username2), nil
+	}
+
+	prepare := []string{
+		fmt.Sprintf("%s vs %s: Games: %d, Wins: %d - %d, Average scores: %.1f - %.1f",
+			username1,
+			username2,
+			stat.Meetings,
+			stat.Wins1,
+			stat.Wins2,
+			stat.Avg1,
+			stat.Avg2),
+	}
+	for i := range meetings {
+		winner := meetings[i].Winner
+		if meetings[i].WinnerTeam != "" {
+			winner = fmt.Sprintf("team %s", meetings[i].WinnerTeam)
+		} else if winner == "" {
+			winner = "draw"
+		}
+		prepare = append(prepare,
+			fmt.Sprintf("\tGameID: %d, %s, Scores: %d - %d, Winner: %s",
+				meetings[i].GameID,
+				meetings[i].FinishedAt.Format("2006-01-02"),
+				meetings[i].Score1,
+				meetings[i].Score2,
+				winner))
+	}
+
+	pretty := strings.Join(prepare, "\n\r")
+	return true, pretty, nil
+}
+
+func (game *Game) GetProfile(username string) (bool, string, error) {
+	profile, err := db.UserProfile(username, formLength)
+	if db.NotFound(err) {
+		return true, fmt.Sprintf("User '%s' is not found", username), nil
+	}
+	if err != nil {
+		return true, databaseError, err
+	}
+
+	user := profile.User
+	winRate, avgScore := 0.0, 0.0
+	if user.Games > 0 {
+		winRate = 100 * float64(user.Wins) / float64(user.Games)
+		avgScore = float64(user.Scores) / float64(user.Games)
+	}
+	prepare := []string{
+		fmt.Sprintf("Profile of %s", user.Name),
+		fmt.Sprintf("\tRating: %.0f, Games: %d, Wins: %d, Win rate: %.1f%%, Average score: %.1f",
+			user.Rating,
+			user.Games,
+			user.Wins,
+			winRate,
+			avgScore),
+	}
+	if profile.LongestWord != "" {
+		prepare = append(prepare,
+			fmt.Sprintf("\tVocabulary: %d words, Average word length: %.1f, Longest word: %s, Favourite word: %s (%d times)",
+				user.WordsCount,
+				profile.AvgWordLength,
+				profile.LongestWord,
+				profile.FavouriteWord,
+				profile.FavouriteCount))
+	}
+	if profile.Form != "" {
+		results := map[byte]string{'W': "wins", 'L': "losses", 'D': "draws"}
+		prepare = append(prepare,
+			fmt.Sprintf("\tForm: %s (from the latest game), Streak: %d %s",
+				profile.Form,
+				profile.Streak,
+				results[profile.Form[0]]))
+	}
+
+	pretty := strings.Join(prepare, "\n\r")
+	return true, pretty, nil
+}
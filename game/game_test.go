@@ -0,0 +1,27 @@
+/**
+ * @file game_test.go
+ * @brief Tests of game commands
+ */
+
+package game
+
+import (
+	// System
+	"strings"
+	"testing"
+	// Third-party
+	// Project
+)
+
+/**
+ * @brief Statistics commands without arguments answer with usage
+ */
+func TestStatCommandsUsage(t *testing.T) {
+	game := &Game{}
+	for _, cmd := range []string{"stat_topusers", "stat_topusers rating", "stat_topwords", "stat_wordtopusers балда", "stat_user", "stat_vs", "profile", "stat_rating"} {
+		play, response, err := game.command(cmd, "user")
+		if !play || err != nil || !strings.HasPrefix(response, "Not correct command") {
+			t.Errorf("'%s': %v, '%s', %v", cmd, play, response, err)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+/**
+ * @file hunt.go
+ * @brief Word hunt
+ *
+ * Timed mode without turns: all users see the same area filled with random letters
+ * and find as many words as they can before time is over. Words are read by paths
+ * of neighbour cells, every cell is used once in a word. Words found by only one user
+ * give double score
+ */
+
+package game
+
+import (
+	// System
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	// Third-party
+
+	// Project
+	"github.com/BaldaGo/balda-go/db"
+	"github.com/BaldaGo/balda-go/logger"
+)
+
+const (
+	ModeHunt    = "hunt" ///< Users find words on random area at the same time
+	huntMinWord = 3      ///< Minimum length of found word
+)
+
+/**
+ * @brief Callback, which sends message from game to users
+ * @param[in] users Logins of receivers (all users of game if empty)
+ * @param[in] msg Message
+ * @param[in] over Flag if game is over after this message
+ *
+ * Used by game, when something happens without message of user (e.g. timer is over)
+ */
+type Notifier func(users []string, msg string, over bool)
+
+/**
+ * @class privateReply
+ * @brief Class, provides message for single user
+ */
+type privateReply struct {
+	user string ///< User's login
+	msg  string ///< Message
+}
+
+/**
+ * @class hunt
+ * @brief Class, provides state of hunt game
+ *
+ * State is guarded by lock of the game, timer works in another goroutine
+ */
+type hunt struct {
+	over     bool                ///< Flag if time is over
+	found    map[string][]string ///< Words found by user
+	finders  map[string][]string ///< Users, who found word
+	deadline time.Time           ///< Time when game is over
+	timer    *time.Timer         ///< Timer, which finishes game
+}
+
+/**
+ * @brief Prepare hunt game on random area
+ *
+ * Timer isn't started until game is saved, see runHunt
+ */
+func (game *Game) startHunt() {
+	game.stopHunt()
+
+	game.square = newRandomSquare(game.AreaSize, game.dictionary, game.rand)
+	game.hunt = &hunt{
+		found:   make(map[string][]string),
+		finders: make(map[string][]string),
+	}
+}
+
+/**
+ * @brief Start timer of hunt game
+ *
+ * Game is finished by timer, results are sent by Notifier
+ */
+func (game *Game) runHunt() {
+	h := game.hunt
+	h.deadline = time.Now().Add(game.HuntTime)
+	h.timer = time.AfterFunc(game.HuntTime, func() { game.finishHunt(h) })
+}
+
+/**
+ * @brief Stop timer of previous hunt game
+ */
+func (game *Game) stopHunt() {
+	if game.hunt != nil && game.hunt.timer != nil {
+		game.hunt.timer.Stop()
+	}
+}
+
+/**
+ * @brief Area and time left
+ */
+func (game *Game) huntArea() string {
+	left := time.Until(game.hunt.deadline) / time.Second * time.Second
+	return fmt.Sprintf("%s\n\rTime left: %s", game.square.StrPrintArea(), left)
+}
+
+/**
+ * @brief Words found by user
+ * @param[in] user User's login
+ */
+func (game *Game) huntWords(user string) string {
+	if len(game.hunt.found[user]) == 0 {
+		return "You haven't found words yet"
+	}
+	return fmt.Sprintf("Your words (%d): %s", len(game.hunt.found[user]), strings.Join(game.hunt.found[user], ", "))
+}
+
+/**
+ * @brief Check word found by user
+ * @param[in] str Word typed by user
+ * @param[in] user User's login
+ * @return Same values as Continue
+ *
+ * Answer is sent only to user by Notifier after lock of the game is released,
+ * so others don't see found words
+ */
+func (game *Game) huntWord(str string, user string) (bool, string, error) {
+	reply := game.checkHuntWord(str, user)
+	if game.Notifier == nil {
+		return true, reply, nil
+	}
+	game.private = append(game.private, privateReply{user: user, msg: reply})
+	return true, "", nil
+}
+
+/**
+ * @brief Check word and add it to words of user
+ * @param[in] str Word typed by user
+ * @param[in] user User's login
+ * @return reply Message for user
+ *
+ * Called under lock of the game, so dictionary is taken together with the state of hunt
+ */
+func (game *Game) checkHuntWord(str string, user string) string {
+	h := game.hunt
+	if h.over {
+		return "Time is over"
+	}
+
+	game.refreshDictionary()
+	word, err := game.dictionary.Normalize(str)
+	if err != nil {
+		return fmt.Sprintf("Invalid word: %s", err.Error())
+	}
+	if utf8.RuneCountInString(word) < huntMinWord {
+		return fmt.Sprintf("Word must have at least %d letters", huntMinWord)
+	}
+	for _, w := range h.found[user] {
+		if w == word {
+			return fmt.Sprintf("You have already found '%s'", word)
+		}
+	}
+	if !game.square.HasPath([]rune(word)) {
+		return fmt.Sprintf("Word '%s' can't be read on area", word)
+	}
+	if !game.dictionary.CheckWord(word) {
+		return fmt.Sprintf("Word '%s' is not in dictionary", word)
+	}
+
+	h.found[user] = append(h.found[user], word)
+	h.finders[word] = append(h.finders[word], user)
+
+	if game.Ranked {
+		if _, err := db.AddWord(user, word, game.Language); err != nil {
+			logger.Log.Critical(err.Error())
+		}
+	}
+	if err := game.logMove(user, db.MoveWord, -1, -1, "", word); err != nil {
+		logger.Log.Critical(err.Error())
+	}
+
+	return fmt.Sprintf("Found '%s' (%d words)", word, len(h.found[user]))
+}
+
+/**
+ * @brief Score of word in hunt game
+ * @param[in] word Found word
+ * @param[in] finders Number of users, who found word
+ */
+func huntScore(word string, finders int) int {
+	sc := utf8.RuneCountInString(word)
+	if finders == 1 {
+		sc *= 2
+	}
+	return sc
+}
+
+/**
+ * @brief Finish hunt game by timer
+ * @param[in] h Hunt game of timer
+ *
+ * Counts scores and sends results by Notifier. Game is finished under its lock,
+ * because users send words at the same time
+ */
+func (game *Game) finishHunt(h *hunt) {
+	game.mu.Lock()
+	if game.hunt != h || h.over {
+		game.mu.Unlock()
+		return
+	}
+	h.over = true
+
+	for user, words := range h.found {
+		for _, w := range words {
+			game.scoreMap[user] += huntScore(w, len(h.finders[w]))
+		}
+	}
+
+	words := make([]string, 0, len(h.finders))
+	for w := range h.finders {
+		words = append(words, w)
+	}
+	sort.Strings(words)
+
+	lines := []string{"Time is over! Found words (unique words give double score):"}
+	for _, w := range words {
+		lines = append(lines, fmt.Sprintf("%s (+%d): %s", w, huntScore(w, len(h.finders[w])), strings.Join(h.finders[w], ", ")))
+	}
+	_, msg, _ := game.gameOver(strings.Join(lines, "\n\r"))
+	game.mu.Unlock()
+
+	if game.Notifier != nil {
+		game.Notifier(nil, msg, true)
+	}
+}
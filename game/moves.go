@@ -0,0 +1,131 @@
+/**
+ * @file moves.go
+ * @brief Search of possible moves
+ *
+ * Contains Move type and methods to find legal moves on the game area
+ */
+
+package game
+
+/**
+ * @class Move
+ * @brief Class, provides one legal move on the game area
+ */
+type Move struct {
+	X      int    ///< Horisontal coordinate of the new letter (column)
+	Y      int    ///< Vertical coordinate of the new letter (row)
+	Letter rune   ///< New letter
+	Word   string ///< Word formed with the new letter
+}
+
+/**
+ * @brief Predicate, check if any player can make a move
+ * @return ok If ok is true, then at least one word can be formed
+ */
+func (area Square) HasMove() bool {
+	return len(area.FindMoves(1)) > 0
+}
+
+/**
+ * @brief Find legal moves on the game area
+ * @param[in] limit Maximum number of moves to find (all moves if limit <= 0)
+ * @return moves Slice of found moves
+ *
+ * Tries every letter of the alphabet in every empty cell, which has a neighbour letter,
+ * and walks all paths through the new letter, cutting them by dictionary prefixes
+ */
+func (area Square) FindMoves(limit int) []Move {
+	var moves []Move
+	found := make(map[Move]bool)
+
+	for x := range area.matrix {
+		for y := range area.matrix[x] {
+			if area.matrix[x][y] != '-' || !area.hasNeighbour(x, y) {
+				continue
+			}
+
+			for _, letter := range area.dictionary.Alphabet() {
+				area.matrix[x][y] = letter
+				for _, word := range area.wordsThrough(x, y) {
+					move := Move{X: y, Y: x, Letter: letter, Word: word}
+					if found[move] || area.wordAlreadyUsed([]rune(word)) {
+						continue
+					}
+					found[move] = true
+					moves = append(moves, move)
+					if limit > 0 && len(moves) >= limit {
+						area.matrix[x][y] = '-'
+						return moves
+					}
+				}
+			}
+			area.matrix[x][y] = '-'
+		}
+	}
+
+	return moves
+}
+
+/**
+ * @brief Predicate, check if cell (x, y) has a letter in a neighbour cell
+ * @param[in] x Row of the cell
+ * @param[in] y Column of the cell
+ */
+func (area Square) hasNeighbour(x int, y int) bool {
+	size := len(area.matrix)
+	for _, d := range [][2]int{{0, -1}, {0, 1}, {-1, 0}, {1, 0}} {
+		i, j := x+d[0], y+d[1]
+		if i >= 0 && j >= 0 && i < size && j < size && area.matrix[i][j] != '-' {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * @brief Find all dictionary words on paths passed through cell (x, y)
+ * @param[in] x Row of the required cell
+ * @param[in] y Column of the required cell
+ * @return words Slice of found words
+ */
+func (area Square) wordsThrough(x int, y int) []string {
+	var words []string
+	visited := make([][]bool, len(area.matrix))
+	for i := range visited {
+		visited[i] = make([]bool, len(area.matrix[i]))
+	}
+
+	var walk func(i int, j int, prefix []rune, through bool)
+	walk = func(i int, j int, prefix []rune, through bool) {
+		if i < 0 || j < 0 || i >= len(area.matrix) || j >= len(area.matrix[i]) ||
+			visited[i][j] || area.matrix[i][j] == '-' {
+			return
+		}
+
+		prefix = append(prefix, area.matrix[i][j])
+		through = through || (i == x && j == y)
+		word := string(prefix)
+
+		if through && area.dictionary.CheckWord(word) {
+			words = append(words, word)
+		}
+		if !area.dictionary.HasPrefix(word) {
+			return
+		}
+
+		visited[i][j] = true
+		walk(i, j-1, prefix, through)
+		walk(i, j+1, prefix, through)
+		walk(i-1, j, prefix, through)
+		walk(i+1, j, prefix, through)
+		visited[i][j] = false
+	}
+
+	for i := range area.matrix {
+		for j := range area.matrix[i] {
+			walk(i, j, make([]rune, 0, len(area.matrix)*len(area.matrix)), false)
+		}
+	}
+
+	return words
+}
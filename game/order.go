@@ -0,0 +1,110 @@
+/**
+ * @file order.go
+ * @brief Turn order
+ *
+ * Contains policies, which arrange users before every game
+ */
+
+package game
+
+import (
+	// System
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	// Third-party
+
+	// Project
+	"github.com/BaldaGo/balda-go/db"
+)
+
+/**
+ * @brief enum of turn order policies
+ */
+const (
+	OrderJoin   = "join"   ///< Users move in order they joined the session
+	OrderRandom = "random" ///< Users are shuffled by random generator of the session
+	OrderRating = "rating" ///< User with the lowest rating moves first
+)
+
+/**
+ * @brief Predicate, check if turn order policy is known
+ * @param[in] policy Name of policy
+ */
+func IsTurnOrder(policy string) bool {
+	switch policy {
+	case OrderJoin, OrderRandom, OrderRating:
+		return true
+	}
+	return false
+}
+
+/**
+ * @brief Choose turn order policy
+ * @param[in] policy Name of policy
+ * @return err Error if policy is unknown or game already started
+ */
+func (game *Game) SetTurnOrder(policy string) error {
+	if game.onStart {
+		return errors.New("Game already started, turn order can't be changed")
+	}
+	if !IsTurnOrder(policy) {
+		return errors.New(fmt.Sprintf("Unknown turn order '%s'", policy))
+	}
+	game.TurnOrder = policy
+	return nil
+}
+
+/**
+ * @brief Arrange users by turn order policy
+ * @return err Error if ratings can't be read
+ *
+ * Order is rotated by number of played games, so every user moves first in turn
+ */
+func (game *Game) arrangeUsers() error {
+	users := make([]string, len(game.joined))
+	copy(users, game.joined)
+
+	switch game.TurnOrder {
+	case OrderRandom:
+		for i, j := range game.rand.Perm(len(users)) {
+			users[i] = game.joined[j]
+		}
+	case OrderRating:
+		ratings, err := db.Ratings(users)
+		if err != nil {
+			return err
+		}
+		sort.SliceStable(users, func(i, j int) bool {
+			return ratings[users[i]] < ratings[users[j]]
+		})
+	}
+
+	if len(users) > 0 {
+		shift := game.rounds % len(users)
+		users = append(users[shift:], users[:shift]...)
+	}
+
+	game.users = users
+	game.stepUser = 0
+	game.rounds++
+	return nil
+}
+
+/**
+ * @brief Turn order of the game
+ * @return str Logins of users in order of their moves (with teams in team play)
+ */
+func (game *Game) Order() string {
+	if !game.Teams {
+		return strings.Join(game.users, ", ")
+	}
+
+	users := make([]string, len(game.users))
+	for i, u := range game.users {
+		users[i] = fmt.Sprintf("%s (team %s)", u, game.team[u])
+	}
+	return strings.Join(users, ", ")
+}
@@ -0,0 +1,184 @@
+/**
+ * @file practice.go
+ * @brief Practice
+ *
+ * Unranked games don't change statistics of users and their lexicons.
+ * Bots can take free places of unranked grid game, bot makes the longest word it finds
+ */
+
+package game
+
+import (
+	// System
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	// Third-party
+
+	// Project
+	"github.com/BaldaGo/balda-go/db"
+	"github.com/BaldaGo/balda-go/logger"
+)
+
+const BotPrefix = "bot#" ///< Prefix of bots' logins, users can't take it
+
+/**
+ * @brief Show or switch practice before game starts
+ * @param[in] args "on" or "off", if it is empty, shows current state
+ * @return Same values as Continue
+ */
+func (game *Game) practice(args []string) (bool, string, error) {
+	if len(args) == 0 || args[0] == "" {
+		if game.Ranked {
+			return true, "Game is ranked", nil
+		}
+		return true, "Game is practice, it doesn't change statistics", nil
+	}
+	if game.onStart {
+		return true, "Game already started, practice can't be changed", nil
+	}
+
+	switch args[0] {
+	case "on":
+		game.Ranked = false
+	case "off":
+		if len(game.bots) > 0 {
+			return true, "Game with bots is always practice", nil
+		}
+		game.Ranked = true
+	default:
+		return true, "Not correct command, use: practice on|off", nil
+	}
+	return true, fmt.Sprintf("Practice is %s", args[0]), nil
+}
+
+/**
+ * @brief Add bots to free places of the game
+ * @param[in] n Number of bots
+ * @return msg Message for users
+ * @return err Error if bots can't be added
+ *
+ * Game with bots becomes practice
+ */
+func (game *Game) AddBots(n int) (string, error) {
+	game.mu.Lock()
+	defer game.mu.Unlock()
+
+	if game.onStart || game.finished {
+		return "", errors.New("Game already started, bots can't be added")
+	}
+	if game.reservation != nil {
+		return "", errors.New("Bots can't play tournament game")
+	}
+	if game.Mode != ModeGrid {
+		return "", errors.New(fmt.Sprintf("Bots play only in %s mode", ModeGrid))
+	}
+	if free := game.MaxUsersPerGame - len(game.users); n <= 0 || n > free {
+		return "", errors.New(fmt.Sprintf("Not correct number of bots, free places: %d", free))
+	}
+
+	added := []string{}
+	for i := 1; len(added) < n; i++ {
+		name := fmt.Sprintf("%s%d", BotPrefix, i)
+		if _, ok := game.scoreMap[name]; ok {
+			continue
+		}
+		if _, err := db.AddBot(name); err != nil {
+			return "", err
+		}
+		if err := game.addUser(name); err != nil {
+			return "", err
+		}
+		game.bots = append(game.bots, name)
+		added = append(added, name)
+	}
+	game.Ranked = false
+
+	return fmt.Sprintf("Bots joined the practice game: %s", strings.Join(added, ", ")), nil
+}
+
+/**
+ * @brief Predicate, check if user is bot of the game
+ * @param[in] login User's login
+ */
+func (game *Game) IsBot(login string) bool {
+	return contains(game.bots, login)
+}
+
+/**
+ * @brief Number of users, who aren't bots
+ */
+func (game *Game) humans() int {
+	return len(game.users) - len(game.bots)
+}
+
+/**
+ * @brief Predicate, check if bot moves now
+ */
+func (game *Game) botTurn() bool {
+	return game.onStart && game.Mode == ModeGrid && game.challenge == nil && !game.onPut &&
+		game.stepUser < len(game.users) && game.IsBot(game.users[game.stepUser])
+}
+
+/**
+ * @brief Make moves of bots until user's step
+ * @return Same values as Continue, response contains all moves of bots
+ */
+func (game *Game) playBots() (bool, string, error) {
+	lines := []string{}
+	for game.botTurn() {
+		play, response, err := game.botMove(game.users[game.stepUser])
+		lines = append(lines, response)
+		if !play || err != nil {
+			return play, strings.Join(lines, "\n\r"), err
+		}
+	}
+	return true, strings.Join(lines, "\n\r"), nil
+}
+
+/**
+ * @brief Move of bot: the longest word or skip if there are no moves
+ * @param[in] bot Bot's login
+ * @return Same values as Continue
+ */
+func (game *Game) botMove(bot string) (bool, string, error) {
+	game.refreshDictionary()
+
+	var best []Move
+	bestLen := 0
+	for _, m := range game.square.FindMoves(0) {
+		switch n := utf8.RuneCountInString(m.Word); {
+		case n > bestLen:
+			best, bestLen = []Move{m}, n
+		case n == bestLen:
+			best = append(best, m)
+		}
+	}
+
+	if len(best) > 0 {
+		m := best[game.rand.Intn(len(best))]
+		game.putting.x, game.putting.y, game.putting.sym = m.X, m.Y, m.Letter
+
+		moves := game.moves
+		play, response, err := game.word(m.Word)
+		if err != nil {
+			return play, response, err
+		}
+		if game.moves != moves {
+			line := fmt.Sprintf("%s: '%c' at (%d, %d), word '%s'", bot, m.Letter, m.X, m.Y, m.Word)
+			if !play {
+				return false, strings.Join([]string{line, response}, "\n\r"), nil
+			}
+			return true, strings.Join([]string{line, game.area()}, "\n\r"), nil
+		}
+		logger.Log.Warningf("Move of %s is rejected: %s", bot, response)
+	}
+
+	play, response, err := game.skip()
+	if !play || err != nil {
+		return play, response, err
+	}
+	return true, fmt.Sprintf("%s skipped", bot), nil
+}
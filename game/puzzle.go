@@ -0,0 +1,446 @@
+/**
+ * @file puzzle.go
+ * @brief Puzzles
+ *
+ * Generator of positions on area, whose best move is unique, and file of puzzles.
+ * Format: one puzzle per line, fields are separated by TAB:
+ * "difficulty letters words x y letter answer", where letters are letters of area
+ * row by row ('-' for empty cells) and words are used words separated by commas.
+ * Lines starting with '#' are comments, the first one is header with parameters of generator:
+ * "# language=ru ruleset=free size=5 ..."
+ */
+
+package game
+
+import (
+	// System
+	"bufio"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	// Third-party
+
+	// Project
+	"github.com/BaldaGo/balda-go/conf"
+	"github.com/BaldaGo/balda-go/db"
+	"github.com/BaldaGo/balda-go/logger"
+)
+
+/**
+ * @brief enum of puzzle difficulties
+ */
+const (
+	DifficultyEasy   = "easy"   ///< Short or popular answer
+	DifficultyMedium = "medium" ///< Answer of medium length or rarely used
+	DifficultyHard   = "hard"   ///< Long answer or answer, which nobody used
+)
+
+/**
+ * @brief enum of difficulty measures
+ */
+const (
+	MeasureLength = "length" ///< Difficulty by length of answer
+	MeasureRarity = "rarity" ///< Difficulty by popularity of answer among players
+)
+
+const (
+	puzzleMaxMoves  = 6  ///< Maximum number of random moves made on area after start word
+	puzzleAttempts  = 50 ///< Number of positions tried for one puzzle
+	popularWord     = 10 ///< Popularity of word, which makes puzzle easy
+	mediumWordLen   = 5  ///< Minimum length of answer of medium puzzle
+	hardWordLen     = 7  ///< Minimum length of answer of hard puzzle
+	puzzleFieldsNum = 7  ///< Number of fields in line of puzzles file
+)
+
+var difficulties = []string{DifficultyEasy, DifficultyMedium, DifficultyHard} ///< All difficulties from the easiest one
+
+/**
+ * @brief Predicate, check if difficulty is known
+ * @param[in] difficulty Name of difficulty
+ */
+func IsDifficulty(difficulty string) bool {
+	return contains(difficulties, difficulty)
+}
+
+/**
+ * @class Puzzle
+ * @brief Class, provides position on area with unique best move
+ */
+type Puzzle struct {
+	Difficulty string   ///< Difficulty of puzzle
+	Letters    string   ///< Letters of area row by row
+	Words      []string ///< Words used on area
+	Answer     Move     ///< The best move
+}
+
+/**
+ * @brief Score of the best move
+ */
+func (p Puzzle) Score() int {
+	return utf8.RuneCountInString(p.Answer.Word)
+}
+
+/**
+ * @brief Restore area of puzzle
+ * @param[in] g Game with dictionary and size of area
+ */
+func (p Puzzle) square(g *Game) Square {
+	square := squareOfLetters(g.AreaSize, g.dictionary, []rune(p.Letters))
+	square.usedWords = append([]string{}, p.Words...)
+	return square
+}
+
+/**
+ * @brief Predicate, check if puzzles have the same area and answer
+ * @param[in] other Other puzzle
+ */
+func (p Puzzle) same(other *Puzzle) bool {
+	return other != nil && p.Letters == other.Letters && p.Answer == other.Answer
+}
+
+/**
+ * @brief Predicate, check if answer or used words of puzzle are offensive
+ * @param[in] g Game with dictionary
+ */
+func (p Puzzle) offensive(g *Game) bool {
+	if g.dictionary.Offensive(p.Answer.Word) {
+		return true
+	}
+	for _, word := range p.Words {
+		if g.dictionary.Offensive(word) {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * @brief Predicate, check if the answer is still the only best move and puzzle isn't offensive
+ * @param[in] g Game with current dictionary and size of area
+ *
+ * Dictionary can be changed by admins after puzzle was generated
+ */
+func (p Puzzle) solvable(g *Game) bool {
+	if p.offensive(g) {
+		return false
+	}
+	square := p.square(g)
+	best, ok := uniqueBest(square.FindMoves(0))
+	return ok && best == p.Answer
+}
+
+/**
+ * @brief Check move in puzzle
+ * @param[in] g Game with dictionary and size of area
+ * @param[in] x Horisontal coordinate of the new letter (column)
+ * @param[in] y Vertical coordinate of the new letter (row)
+ * @param[in] letter New letter
+ * @param[in] word Word made with the new letter
+ * @return score Score of move, 0 if move is wrong
+ * @return word Normalized word or error if letter or word is invalid
+ */
+func (p Puzzle) check(g *Game, x int, y int, letter string, word string) (int, string, error) {
+	if x < 0 || y < 0 || x >= g.AreaSize || y >= g.AreaSize {
+		return 0, "", errors.New("Invalid coordinates")
+	}
+	sym, err := g.dictionary.NormalizeLetter(letter)
+	if err != nil {
+		return 0, "", errors.New(fmt.Sprintf("Invalid letter: %s", err.Error()))
+	}
+	word, err = g.dictionary.Normalize(word)
+	if err != nil {
+		return 0, "", errors.New(fmt.Sprintf("Invalid word: %s", err.Error()))
+	}
+
+	square := p.square(g)
+	if !square.CheckWord(y, x, sym, []rune(word)) {
+		return 0, word, nil
+	}
+	return utf8.RuneCountInString(word), word, nil
+}
+
+/**
+ * @class Generator
+ * @brief Class, generates puzzles
+ */
+type Generator struct {
+	game    *Game      ///< Game with dictionary and size of area
+	rand    *rand.Rand ///< Random generator
+	measure string     ///< Measure of difficulty (length, rarity)
+}
+
+/**
+ * @brief Create generator of puzzles
+ * @param[in] cfg Configuration of games (language, ruleset, size of area)
+ * @param[in] seed Seed, the same seed and dictionary give the same puzzles
+ * @param[in] measure Measure of difficulty, rarity needs database
+ * @return gen Generator or error if dictionary or measure is unknown
+ */
+func NewGenerator(cfg conf.GameConf, seed int64, measure string) (*Generator, error) {
+	if measure != MeasureLength && measure != MeasureRarity {
+		return nil, errors.New(fmt.Sprintf("Unknown difficulty measure '%s'", measure))
+	}
+
+	g := &Game{AreaSize: cfg.AreaSize}
+	if err := g.setDictionary(cfg.Language, cfg.Ruleset); err != nil {
+		return nil, err
+	}
+	return &Generator{game: g, rand: rand.New(rand.NewSource(seed)), measure: measure}, nil
+}
+
+/**
+ * @brief Generate puzzles
+ * @param[in] count Number of puzzles
+ * @param[in] difficulty Difficulty of puzzles, any difficulty if it is empty
+ * @return puzzles Generated puzzles or error if it occured
+ *
+ * Fails, if puzzle isn't found in puzzleAttempts positions
+ */
+func (gen *Generator) Generate(count int, difficulty string) ([]Puzzle, error) {
+	if difficulty != "" && !IsDifficulty(difficulty) {
+		return nil, errors.New(fmt.Sprintf("Unknown difficulty '%s'", difficulty))
+	}
+
+	puzzles := []Puzzle{}
+	for len(puzzles) < count {
+		p, err := gen.puzzle(difficulty)
+		if err != nil {
+			return puzzles, err
+		}
+		puzzles = append(puzzles, *p)
+	}
+	return puzzles, nil
+}
+
+/**
+ * @brief Generate one puzzle
+ * @param[in] difficulty Difficulty of puzzle, any difficulty if it is empty
+ * @return p Puzzle or error if it isn't found
+ *
+ * Puzzles with offensive answer or used words are skipped
+ */
+func (gen *Generator) puzzle(difficulty string) (*Puzzle, error) {
+	for i := 0; i < puzzleAttempts; i++ {
+		square, err := gen.position()
+		if err != nil {
+			return nil, err
+		}
+
+		answer, ok := uniqueBest(square.FindMoves(0))
+		if !ok {
+			continue
+		}
+
+		level, err := gen.difficulty(answer.Word)
+		if err != nil {
+			return nil, err
+		}
+		if difficulty != "" && level != difficulty {
+			continue
+		}
+
+		p := &Puzzle{Difficulty: level, Letters: square.letters(), Words: square.usedWords, Answer: answer}
+		if p.offensive(gen.game) {
+			continue
+		}
+		return p, nil
+	}
+	return nil, errors.New(fmt.Sprintf("Puzzle isn't found in %d attempts", puzzleAttempts))
+}
+
+/**
+ * @brief Random position: start word and random moves after it
+ * @return square Area or error if dictionary has no words with length of area
+ */
+func (gen *Generator) position() (Square, error) {
+	g := gen.game
+	word := g.dictionary.RandWordOfAS(g.AreaSize, gen.rand)
+	if word == "" {
+		return Square{}, errors.New(fmt.Sprintf("No start words with length %d in '%s' dictionary", g.AreaSize, g.Language))
+	}
+
+	square := NewSquare(g.AreaSize, g.dictionary, word)
+	for n := gen.rand.Intn(puzzleMaxMoves) + 1; n > 0; n-- {
+		moves := square.FindMoves(0)
+		if len(moves) == 0 {
+			break
+		}
+		m := moves[gen.rand.Intn(len(moves))]
+		square.CheckWord(m.Y, m.X, m.Letter, []rune(m.Word))
+	}
+	return square, nil
+}
+
+/**
+ * @brief The best move, if it is unique
+ * @param[in] moves All legal moves
+ * @return best The best move
+ * @return ok False if there are no moves or several moves give the best score
+ */
+func uniqueBest(moves []Move) (Move, bool) {
+	var best Move
+	bestScore, count := 0, 0
+	for _, m := range moves {
+		sc := utf8.RuneCountInString(m.Word)
+		switch {
+		case sc > bestScore:
+			best, bestScore, count = m, sc, 1
+		case sc == bestScore:
+			count++
+		}
+	}
+	return best, count == 1
+}
+
+/**
+ * @brief Difficulty of puzzle by its answer
+ * @param[in] word Answer
+ * @return difficulty Name of difficulty or error if database failed
+ */
+func (gen *Generator) difficulty(word string) (string, error) {
+	if gen.measure == MeasureRarity {
+		popularity, err := db.WordsPopularity(gen.game.Language, []string{word})
+		if err != nil {
+			return "", err
+		}
+		switch {
+		case popularity[word] == 0:
+			return DifficultyHard, nil
+		case popularity[word] < popularWord:
+			return DifficultyMedium, nil
+		}
+		return DifficultyEasy, nil
+	}
+
+	switch n := utf8.RuneCountInString(word); {
+	case n >= hardWordLen:
+		return DifficultyHard, nil
+	case n >= mediumWordLen:
+		return DifficultyMedium, nil
+	}
+	return DifficultyEasy, nil
+}
+
+/**
+ * @brief Write puzzles into file
+ * @param[in] path Path to file
+ * @param[in] header Comment in the first line
+ * @param[in] puzzles Puzzles
+ * @return err Error if it occured
+ */
+func SavePuzzles(path string, header string, puzzles []Puzzle) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := bufio.NewWriter(file)
+	fmt.Fprintf(w, "# %s\n", header)
+	for _, p := range puzzles {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%c\t%s\n",
+			p.Difficulty, p.Letters, strings.Join(p.Words, ","), p.Answer.X, p.Answer.Y, p.Answer.Letter, p.Answer.Word)
+	}
+	return w.Flush()
+}
+
+/**
+ * @brief Read puzzles from file
+ * @param[in] path Path to file
+ * @param[in] cfg Configuration of games (language, ruleset, size of area)
+ * @return puzzles Puzzles or error if file can't be read or it is made for another game
+ */
+func LoadPuzzles(path string, cfg conf.GameConf) ([]Puzzle, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	puzzles := []Puzzle{}
+	scanner := bufio.NewScanner(file)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if n == 1 && strings.HasPrefix(line, "#") {
+			if err := checkPuzzlesHeader(line, cfg); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		p, err := parsePuzzle(line, cfg.AreaSize)
+		if err != nil {
+			logger.Log.Warningf("Puzzle in line %d of '%s' is skipped: %s", n, path, err.Error())
+			continue
+		}
+		puzzles = append(puzzles, *p)
+	}
+
+	return puzzles, scanner.Err()
+}
+
+/**
+ * @brief Check header of puzzles file
+ * @param[in] line The first line of file
+ * @param[in] cfg Configuration of games
+ * @return err Error if language, ruleset or size of area differs from configuration
+ *
+ * Parameters, which are absent in header, aren't checked
+ */
+func checkPuzzlesHeader(line string, cfg conf.GameConf) error {
+	expected := map[string]string{
+		"language": cfg.Language,
+		"ruleset":  cfg.Ruleset,
+		"size":     strconv.Itoa(cfg.AreaSize),
+	}
+	for _, field := range strings.Fields(strings.TrimPrefix(line, "#")) {
+		kv := strings.SplitN(field, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		if value, ok := expected[kv[0]]; ok && kv[1] != value {
+			return errors.New(fmt.Sprintf("Puzzles are made for %s '%s', but game has '%s'", kv[0], kv[1], value))
+		}
+	}
+	return nil
+}
+
+/**
+ * @brief Parse line of puzzles file
+ * @param[in] line Line
+ * @param[in] size Length side of area
+ * @return p Puzzle or error if line is malformed
+ */
+func parsePuzzle(line string, size int) (*Puzzle, error) {
+	fields := strings.Split(line, "\t")
+	if len(fields) != puzzleFieldsNum {
+		return nil, errors.New(fmt.Sprintf("%d fields are expected", puzzleFieldsNum))
+	}
+	if !IsDifficulty(fields[0]) {
+		return nil, errors.New(fmt.Sprintf("unknown difficulty '%s'", fields[0]))
+	}
+	if utf8.RuneCountInString(fields[1]) != size*size {
+		return nil, errors.New(fmt.Sprintf("area isn't %dx%d", size, size))
+	}
+	x, errX := strconv.Atoi(fields[3])
+	y, errY := strconv.Atoi(fields[4])
+	letter := []rune(fields[5])
+	if errX != nil || errY != nil || len(letter) != 1 {
+		return nil, errors.New("malformed answer")
+	}
+
+	return &Puzzle{
+		Difficulty: fields[0],
+		Letters:    fields[1],
+		Words:      strings.Split(fields[2], ","),
+		Answer:     Move{X: x, Y: y, Letter: letter[0], Word: fields[6]},
+	}, nil
+}
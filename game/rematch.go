@@ -0,0 +1,129 @@
+/**
+ * @file rematch.go
+ * @brief Rematch
+ *
+ * After the game is over, users vote for rematch with the same rules,
+ * otherwise the session is cleared for new users
+ */
+
+package game
+
+import (
+	// System
+	"errors"
+
+	// Third-party
+
+	// Project
+	"github.com/BaldaGo/balda-go/db"
+)
+
+/**
+ * @brief Predicate, check if game is running
+ */
+func (game *Game) Started() bool {
+	return game.onStart
+}
+
+/**
+ * @brief Predicate, check if game is over and users can vote for rematch
+ */
+func (game *Game) Finished() bool {
+	return game.finished
+}
+
+/**
+ * @brief Vote for rematch
+ * @param[in] user User's login
+ * @return votes Number of users voted for rematch
+ * @return all Flag if all users voted, bots always agree
+ * @return err Error if game isn't over or user isn't in game
+ */
+func (game *Game) VoteRematch(user string) (int, bool, error) {
+	game.mu.Lock()
+	defer game.mu.Unlock()
+
+	if !game.finished {
+		return 0, false, errors.New("Game isn't over")
+	}
+	if _, ok := game.scoreMap[user]; !ok {
+		return 0, false, errors.New("You aren't in this game")
+	}
+	if game.reservation != nil {
+		return 0, false, errors.New("Tournament game can't be rematched, type 'leave' to return to lobby")
+	}
+
+	game.rematch[user] = true
+	return len(game.rematch), len(game.rematch) == len(game.joined)-len(game.bots), nil
+}
+
+/**
+ * @brief Prepare rematch with the same users and rules
+ * @return err Error if it occured
+ *
+ * Creates new game in database, start word is chosen again when game starts
+ */
+func (game *Game) Restart() error {
+	game.mu.Lock()
+	defer game.mu.Unlock()
+
+	if err := game.newSession(); err != nil {
+		return err
+	}
+
+	for _, login := range game.joined {
+		game.scoreMap[login] = 0
+		if _, err := db.NewUserInSession(login, game.dbGameID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/**
+ * @brief Clear session for new users
+ * @return err Error if it occured
+ *
+ * Rules of the session are kept, rules changed by tournament game are restored.
+ * Practice flag is kept, so session of game with bots stays practice until 'practice off'
+ */
+func (game *Game) Reset() error {
+	game.mu.Lock()
+	defer game.mu.Unlock()
+
+	if err := game.newSession(); err != nil {
+		return err
+	}
+
+	game.users = nil
+	game.joined = nil
+	game.bots = nil
+	game.release()
+	game.rounds = 0
+	return nil
+}
+
+/**
+ * @brief Create new game in database and clear state of the previous game
+ * @return err Error if it occured
+ */
+func (game *Game) newSession() error {
+	res, err := db.StartGame()
+	if err != nil {
+		return err
+	}
+
+	game.stopHunt()
+
+	game.dbGameID = res.ID
+	game.onStart = false
+	game.onPut = false
+	game.finished = false
+	game.rematch = nil
+	game.stepUser = 0
+	game.skipped = 0
+	game.moves = 0
+	game.square = Square{}
+	game.scoreMap = make(map[string]int)
+	return nil
+}
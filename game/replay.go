@@ -0,0 +1,131 @@
+/**
+ * @file replay.go
+ * @brief Replay of games
+ *
+ * Every started game saves its seed and setup, every move is saved into the move log,
+ * so the game can be played again move by move
+ */
+
+package game
+
+import (
+	// System
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	// Third-party
+
+	// Project
+	"github.com/BaldaGo/balda-go/db"
+)
+
+/**
+ * @brief Save move into the move log of the game
+ * @param[in] user Login of the user, who made move
+ * @param[in] kind Kind of move (db.MovePut, db.MoveSkip, db.MoveUndo)
+ * @param[in] x Horisontal coordinate of the new letter (column)
+ * @param[in] y Vertical coordinate of the new letter (row)
+ * @param[in] letter New letter
+ * @param[in] word Word formed with the new letter
+ * @return err Error if it occured
+ */
+func (game *Game) logMove(user string, kind string, x int, y int, letter string, word string) error {
+	game.moves++
+	_, err := db.AddMove(game.dbGameID, game.moves, user, kind, x, y, letter, word)
+	return err
+}
+
+/**
+ * @brief Replay finished or running game
+ * @param[in] id Id of the game in database
+ * @return Same values as Continue
+ *
+ * Start word is chosen again by recorded seed and policy to check that the game is reproduced,
+ * then all moves are applied to the area with the current dictionary
+ */
+func (game *Game) replay(id int) (bool, string, error) {
+	session, moves, err := db.GameRecord(uint(id))
+	if err != nil {
+		return true, fmt.Sprintf("Game #%d is not found", id), nil
+	}
+	if session.Mode == ModeClassic || session.Mode == ModeHunt {
+		return true, fmt.Sprintf("Game #%d is played in %s mode, it can't be replayed", id, session.Mode), nil
+	}
+	if session.StartWord == "" {
+		return true, fmt.Sprintf("Game #%d can't be replayed, its setup isn't recorded", id), nil
+	}
+
+	r := &Game{
+		AreaSize:    utf8.RuneCountInString(session.StartWord),
+		StartPolicy: session.StartPolicy,
+		startConf:   game.startConf,
+		scoreMap:    make(map[string]int),
+	}
+	if err := r.setDictionary(session.Language, session.Ruleset); err != nil {
+		return true, fmt.Sprintf("Game #%d can't be replayed: %s", id, err.Error()), nil
+	}
+	r.SetSeed(session.Seed)
+
+	lines := []string{
+		fmt.Sprintf("Game #%d: language %s, ruleset %s, seed %d", id, session.Language, session.Ruleset, session.Seed),
+		fmt.Sprintf("Start word: %s (%s)", session.StartWord, session.StartPolicy),
+	}
+	if session.StartPolicy != StartAdmin {
+		if word, err := r.chooseStartWord(); err != nil || word != session.StartWord {
+			lines = append(lines, fmt.Sprintf("Warning: seed gives start word '%s' now, dictionary or configuration changed", word))
+		}
+	}
+
+	r.users = strings.Split(session.Players, ",")
+	for _, u := range r.users {
+		r.scoreMap[u] = 0
+	}
+	lines = append(lines, fmt.Sprintf("Players: %s", strings.Join(r.users, ", ")))
+
+	r.square = NewSquare(r.AreaSize, r.dictionary, session.StartWord)
+	for _, m := range moves {
+		line, err := r.replayMove(m)
+		if err != nil {
+			lines = append(lines, fmt.Sprintf("%d. %s: %s", m.Number, m.User.Name, err.Error()))
+			break
+		}
+		lines = append(lines, line)
+	}
+
+	lines = append(lines, r.area(), r.standings())
+	return true, strings.Join(lines, "\n\r"), nil
+}
+
+/**
+ * @brief Apply one move of the move log
+ * @param[in] m Move from the move log
+ * @return line Description of the move or error if move can't be applied
+ */
+func (game *Game) replayMove(m db.GameMove) (string, error) {
+	if m.Kind == db.MoveSkip {
+		return fmt.Sprintf("%d. %s: skip", m.Number, m.User.Name), nil
+	}
+	if m.Kind == db.MoveUndo {
+		if !game.square.Undo() {
+			return "", errors.New("nothing to roll back, replay stopped")
+		}
+		sc := utf8.RuneCountInString(m.Word)
+		game.scoreMap[m.User.Name] -= sc
+		return fmt.Sprintf("%d. %s: word '%s' is rolled back after challenge (-%d)", m.Number, m.User.Name, m.Word, sc), nil
+	}
+
+	letter := []rune(m.Letter)
+	if m.Kind != db.MovePut || len(letter) != 1 ||
+		m.X < 0 || m.Y < 0 || m.X >= game.AreaSize || m.Y >= game.AreaSize {
+		return "", errors.New("malformed move, replay stopped")
+	}
+	if !game.square.CheckWord(m.Y, m.X, letter[0], []rune(m.Word)) {
+		return "", errors.New(fmt.Sprintf("word '%s' can't be made now, replay stopped", m.Word))
+	}
+
+	sc := utf8.RuneCountInString(m.Word)
+	game.scoreMap[m.User.Name] += sc
+	return fmt.Sprintf("%d. %s: '%s' at (%d, %d), word '%s' (+%d)", m.Number, m.User.Name, m.Letter, m.X, m.Y, m.Word, sc), nil
+}
@@ -0,0 +1,193 @@
+/**
+ * @file season.go
+ * @brief Seasons
+ *
+ * Leaderboards of periods: today, this week (from Monday), this month and seasons created by admins.
+ * Final standings of season are archived after its end
+ */
+
+package game
+
+import (
+	// System
+	"fmt"
+	"strings"
+	"time"
+
+	// Third-party
+
+	// Project
+	"github.com/BaldaGo/balda-go/db"
+)
+
+/**
+ * @brief enum of periods of leaderboards
+ */
+const (
+	PeriodAll   = "all"   ///< All-time counters of users
+	PeriodToday = "today" ///< Games finished today
+	PeriodWeek  = "week"  ///< Games finished this week
+	PeriodMonth = "month" ///< Games finished this month
+)
+
+const dateFormat = "2006-01-02" ///< Format of dates of seasons
+
+var periods = []string{PeriodAll, PeriodToday, PeriodWeek, PeriodMonth} ///< Periods, which aren't seasons
+
+/**
+ * @brief Bounds of period
+ * @param[in] period Name of period
+ * @param[in] now Current time
+ * @return start Start of period
+ * @return end End of period (excluded)
+ * @return ok False if period isn't one of today, week, month
+ */
+func periodRange(period string, now time.Time) (time.Time, time.Time, bool) {
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch period {
+	case PeriodToday:
+		return day, day.AddDate(0, 0, 1), true
+	case PeriodWeek:
+		start := day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+		return start, start.AddDate(0, 0, 7), true
+	case PeriodMonth:
+		start := day.AddDate(0, 0, 1-day.Day())
+		return start, start.AddDate(0, 1, 0), true
+	}
+	return time.Time{}, time.Time{}, false
+}
+
+/**
+ * @brief Top of users in period
+ * @param[in] mode Mode of sorting (score, games, wins)
+ * @param[in] limit Number of users
+ * @param[in] period Name of period or season
+ * @return Same values as Continue
+ *
+ * Top of archived season is taken from its final standings
+ */
+func (game *Game) GetTopUsersByPeriod(mode string, limit int, period string) (bool, string, error) {
+	if mode == "rating" {
+		return true, "Rating isn't counted by periods, use: stat_topusers rating <limit>", nil
+	}
+
+	var res []db.PeriodStat
+	var err error
+	if start, end, ok := periodRange(period, time.Now()); ok {
+		res, err = db.PeriodTop(mode, start, end, uint(limit), 0)
+	} else {
+		season, errSeason := db.SeasonByName(period)
+		if errSeason != nil {
+			return true, fmt.Sprintf("Unknown period '%s'. Available: %s or season (stat_seasons)", period, strings.Join(periods, ", ")), nil
+		}
+		if season.Archived {
+			res, err = db.SeasonTop(season.ID, mode, uint(limit), 0)
+		} else {
+			res, err = db.PeriodTop(mode, season.Start, season.End, uint(limit), 0)
+		}
+	}
+	if err != nil {
+		return true, databaseError, err
+	}
+
+	var prepare []string
+	for i := range res {
+		prepare = append(prepare,
+			fmt.Sprintf("Login: %s, Scores: %d, Games: %d, Wins: %d",
+				res[i].Name,
+				res[i].Scores,
+				res[i].Games,
+				res[i].Wins))
+	}
+
+	pretty := strings.Join(prepare, "\n\r")
+	return true, pretty, nil
+}
+
+/**
+ * @brief List of seasons
+ * @return Same values as Continue
+ */
+func (game *Game) GetSeasons() (bool, string, error) {
+	seasons, err := db.Seasons()
+	if err != nil {
+		return true, databaseError, err
+	}
+	if len(seasons) == 0 {
+		return true, "There are no seasons", nil
+	}
+
+	var prepare []string
+	for i := range seasons {
+		state := ""
+		if seasons[i].Archived {
+			state = " (archived)"
+		}
+		prepare = append(prepare,
+			fmt.Sprintf("Season: %s, %s - %s%s",
+				seasons[i].Name,
+				seasons[i].Start.Format(dateFormat),
+				seasons[i].End.AddDate(0, 0, -1).Format(dateFormat),
+				state))
+	}
+
+	pretty := strings.Join(prepare, "\n\r")
+	return true, pretty, nil
+}
+
+/**
+ * @brief Create season
+ * @param[in] name Name of season
+ * @param[in] first The first day of season (YYYY-MM-DD)
+ * @param[in] last The last day of season (YYYY-MM-DD)
+ * @return msg Message for admin
+ * @return err Error if database failed
+ */
+func CreateSeason(name string, first string, last string) (string, error) {
+	if contains(periods, name) {
+		return fmt.Sprintf("Name '%s' is reserved for period", name), nil
+	}
+	start, errStart := time.ParseInLocation(dateFormat, first, time.Local)
+	end, errEnd := time.ParseInLocation(dateFormat, last, time.Local)
+	if errStart != nil || errEnd != nil {
+		return "Not correct date, use YYYY-MM-DD", nil
+	}
+	if end.Before(start) {
+		return "Season ends before it starts", nil
+	}
+	if _, err := db.SeasonByName(name); err == nil {
+		return fmt.Sprintf("Season '%s' already exists", name), nil
+	}
+
+	if _, err := db.CreateSeason(name, start, end.AddDate(0, 0, 1)); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Season '%s' is created: %s - %s", name, first, last), nil
+}
+
+/**
+ * @brief Archive final standings of season
+ * @param[in] name Name of season
+ * @return msg Message for admin
+ * @return err Error if database failed
+ *
+ * Season can be archived only after its end
+ */
+func ArchiveSeason(name string) (string, error) {
+	season, err := db.SeasonByName(name)
+	if err != nil {
+		return fmt.Sprintf("Season '%s' is not found", name), nil
+	}
+	if season.Archived {
+		return fmt.Sprintf("Season '%s' is already archived", name), nil
+	}
+	if time.Now().Before(season.End) {
+		return fmt.Sprintf("Season '%s' isn't over yet", name), nil
+	}
+
+	n, err := db.ArchiveSeason(season)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Season '%s' is archived: %d players", name, n), nil
+}
@@ -10,6 +10,7 @@ package game
 import (
 	// System
 	"fmt"
+	"math/rand"
 	"strconv"
 	"strings"
 
@@ -25,18 +26,78 @@ import (
  * @brief Class, provides gaming area
  */
 type Square struct {
-	matrix    [][]rune ///< Matrix of symbols - gaming area
-	usedWords []string ///< Array of used words
+	matrix     [][]rune         ///< Matrix of symbols - gaming area
+	usedWords  []string         ///< Array of used words
+	history    []squareMove     ///< Moves made on area, the last one can be undone
+	dictionary *dict.Dictionary ///< Dictionary of the game language
+}
+
+/**
+ * @class squareMove
+ * @brief Class, provides letter and word added to area by one move
+ */
+type squareMove struct {
+	x    int    ///< Horisontal coordinate of the new letter
+	y    int    ///< Vertical coordinate of the new letter
+	word string ///< Word made with the new letter
 }
 
 /**
  * @brief Constructor of Square
  * @param[in] size Length side of the gaming area
+ * @param[in] d Dictionary of the game language
+ * @param[in] word Start word with length size
+ * @return Pointer to a new Squere object
+ *
+ * Create new Square and initialize them with start word in the middle line
+ */
+func NewSquare(size int, d *dict.Dictionary, word string) Square {
+	area := emptySquare(size)
+	area.dictionary = d
+
+	area.addUsedWord(word)
+	line := (size - 1) / 2
+	for i := range area.matrix[line] {
+		area.matrix[line][i] = []rune(word)[i]
+	}
+
+	return area
+}
+
+/**
+ * @brief Constructor of Square filled with random letters
+ * @param[in] size Length side of the gaming area
+ * @param[in] d Dictionary of the game language
+ * @param[in] r Random generator of the game session
  * @return Pointer to a new Squere object
  *
- * Create new Square and initialize them with random word
+ * Letters are taken from random words of the dictionary,
+ * so frequent letters appear more often
+ */
+func newRandomSquare(size int, d *dict.Dictionary, r *rand.Rand) Square {
+	area := emptySquare(size)
+	area.dictionary = d
+
+	for i := range area.matrix {
+		for j := range area.matrix[i] {
+			word := []rune(d.RandWordOfAS(size, r))
+			if len(word) == 0 {
+				alphabet := d.Alphabet()
+				area.matrix[i][j] = alphabet[r.Intn(len(alphabet))]
+				continue
+			}
+			area.matrix[i][j] = word[r.Intn(len(word))]
+		}
+	}
+
+	return area
+}
+
+/**
+ * @brief Create Square without any letters
+ * @param[in] size Length side of the gaming area
  */
-func NewSquare(size int) Square {
+func emptySquare(size int) Square {
 	var area Square
 	area.matrix = make([][]rune, size)
 
@@ -47,14 +108,6 @@ func NewSquare(size int) Square {
 		}
 	}
 
-	word := dict.RandWordOfAS()
-	//word := "ворон"
-	area.addUsedWord(word)
-	line := (size - 1) / 2
-	for i := range area.matrix[line] {
-		area.matrix[line][i] = []rune(word)[i]
-	}
-
 	return area
 }
 
@@ -173,7 +226,7 @@ func (area Square) findFull(findX int, findY int, x int, y int, word []rune, che
 		return 0
 	}
 
-	areaCopy := NewSquare(len(area.matrix[0]))
+	areaCopy := emptySquare(len(area.matrix[0]))
 	defer areaCopy.destructor() //to kill him by garbage collector
 	areaCopy.deepCopy(area)
 	areaCopy.matrix[x][y] = '!'
@@ -211,18 +264,18 @@ func (area Square) findFull(findX int, findY int, x int, y int, word []rune, che
  * Before calling findFull method, this method checks that:
  * 	1) Сandidate word wasn't userd already
  *	2) The new symbol will not overlap with the existing one.
- *	3) Candidate word is real word of the Russian Language (check in dictionary)
+ *	3) Candidate word is real word of the game language (check in dictionary)
  *	4) There is a letter on area with which candidate word begins.
  */
 func (area *Square) CheckWord(x int, y int, symbol rune, word []rune) bool {
 
 	word = []rune(strings.ToLower(string(word)))
 
-	if area.wordAlreadyUsed(word) || area.matrix[x][y] != '-' || !dict.CheckWord(string(word)) {
+	if area.wordAlreadyUsed(word) || area.matrix[x][y] != '-' || !area.dictionary.CheckWord(string(word)) {
 		return false
 	}
 
-	tempArea := NewSquare(len(area.matrix[0]))
+	tempArea := emptySquare(len(area.matrix[0]))
 	defer tempArea.destructor() //to kill him by garbage collector
 	tempArea.deepCopy(*area)
 
@@ -234,6 +287,7 @@ func (area *Square) CheckWord(x int, y int, symbol rune, word []rune) bool {
 				(tempArea.findFull(x, y, i, j, word, false) != 0) {
 				area.deepCopy(tempArea)
 				area.addUsedWord(string(word))
+				area.history = append(area.history, squareMove{x: x, y: y, word: string(word)})
 				logger.Log.Debugf("New word '%s' added", string(word))
 				return true
 			}
@@ -245,6 +299,50 @@ func (area *Square) CheckWord(x int, y int, symbol rune, word []rune) bool {
 	return false
 }
 
+/**
+ * @brief Undo the last move made on area
+ * @return ok False if there are no moves to undo
+ *
+ * Removes the letter of the last move and its word from used words
+ */
+func (area *Square) Undo() bool {
+	if len(area.history) == 0 {
+		return false
+	}
+
+	m := area.history[len(area.history)-1]
+	area.history = area.history[:len(area.history)-1]
+	area.matrix[m.x][m.y] = '-'
+	for i := len(area.usedWords) - 1; i >= 0; i-- {
+		if area.usedWords[i] == m.word {
+			area.usedWords = append(area.usedWords[:i], area.usedWords[i+1:]...)
+			break
+		}
+	}
+
+	logger.Log.Debugf("Word '%s' undone", m.word)
+	return true
+}
+
+/**
+ * @brief Predicate, check if word can be read on area by path of neighbour cells
+ * @param[in] word Word to find
+ */
+func (area Square) HasPath(word []rune) bool {
+	if len(word) == 0 {
+		return false
+	}
+
+	for i := range area.matrix {
+		for j := range area.matrix[i] {
+			if area.matrix[i][j] == word[0] && area.findFull(-1, -1, i, j, word, true) != 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (area *Square) IsFull() bool {
 	for i := range area.matrix {
 		for j := range area.matrix[i] {
@@ -0,0 +1,174 @@
+/**
+ * @file start.go
+ * @brief Start word selection
+ *
+ * Contains policies, which choose the start word of the game
+ */
+
+package game
+
+import (
+	// System
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+	"unicode/utf8"
+
+	// Third-party
+
+	// Project
+	"github.com/BaldaGo/balda-go/logger"
+)
+
+/**
+ * @brief enum of start word policies
+ */
+const (
+	StartRandom   = "random"   ///< Random word of the dictionary
+	StartAdmin    = "admin"    ///< Word chosen by admin, random word if it isn't chosen
+	StartList     = "list"     ///< Random word from curated list of the dictionary
+	StartBalanced = "balanced" ///< Random word, which gives at least MinMoves legal moves
+)
+
+/**
+ * @brief Predicate, check if policy is known
+ * @param[in] policy Name of policy
+ */
+func IsStartPolicy(policy string) bool {
+	switch policy {
+	case StartRandom, StartAdmin, StartList, StartBalanced:
+		return true
+	}
+	return false
+}
+
+/**
+ * @brief Set seed of session random generator
+ * @param[in] seed Seed, the same seed gives the same random choices
+ */
+func (game *Game) SetSeed(seed int64) {
+	game.seed = seed
+	game.rand = rand.New(rand.NewSource(seed))
+}
+
+/**
+ * @brief Set seed of the next game
+ * @param[in] seed Seed, the same seed, policy and dictionary give the same start word
+ * @return err Error if game already started
+ */
+func (game *Game) SetStartSeed(seed int64) error {
+	if game.onStart {
+		return errors.New("Game already started, seed can't be changed")
+	}
+	game.SetSeed(seed)
+	return nil
+}
+
+/**
+ * @brief Set seed of session random generator from current time
+ */
+func (game *Game) randomSeed() {
+	game.SetSeed(time.Now().UnixNano())
+}
+
+/**
+ * @brief Choose policy of start word selection
+ * @param[in] policy Name of policy
+ * @return err Error if policy is unknown or game already started
+ */
+func (game *Game) SetStartPolicy(policy string) error {
+	if game.onStart {
+		return errors.New("Game already started, start policy can't be changed")
+	}
+	if !IsStartPolicy(policy) {
+		return errors.New(fmt.Sprintf("Unknown start policy '%s'", policy))
+	}
+	game.StartPolicy = policy
+	return nil
+}
+
+/**
+ * @brief Choose start word of the next game
+ * @param[in] word Word typed by admin
+ * @return word Normalized word or error if word can't be a start word
+ *
+ * Chosen word is used once, next games choose start word by policy again
+ */
+func (game *Game) SetStartWord(word string) (string, error) {
+	if game.onStart {
+		return "", errors.New("Game already started, start word can't be changed")
+	}
+
+	game.refreshDictionary()
+	word, err := game.dictionary.Normalize(word)
+	if err != nil {
+		return "", err
+	}
+	if utf8.RuneCountInString(word) != game.AreaSize {
+		return "", errors.New(fmt.Sprintf("Start word must have %d letters", game.AreaSize))
+	}
+	if !game.dictionary.CheckWord(word) {
+		return "", errors.New(fmt.Sprintf("Word '%s' is not in dictionary", word))
+	}
+
+	game.startWord = word
+	return word, nil
+}
+
+/**
+ * @brief Choose start word by policy
+ * @return word Start word or error if dictionary has no words with length AreaSize
+ */
+func (game *Game) chooseStartWord() (string, error) {
+	word := ""
+
+	switch {
+	case game.startWord != "":
+		word = game.startWord
+		game.startWord = ""
+	case game.StartPolicy == StartList:
+		if word = game.dictionary.RandStartWord(game.AreaSize, game.rand); word == "" {
+			logger.Log.Warningf("No curated start words with length %d in '%s' dictionary", game.AreaSize, game.Language)
+		}
+	case game.StartPolicy == StartBalanced:
+		word = game.balancedStartWord()
+	case game.StartPolicy == StartAdmin:
+		logger.Log.Warning("Start word isn't chosen by admin, random one is used")
+	}
+
+	if word == "" {
+		word = game.dictionary.RandWordOfAS(game.AreaSize, game.rand)
+	}
+	if word == "" {
+		return "", errors.New(fmt.Sprintf("No start words with length %d in '%s' dictionary", game.AreaSize, game.Language))
+	}
+	return word, nil
+}
+
+/**
+ * @brief Find start word, which gives at least MinMoves legal moves
+ * @return word Found word or word with the most moves among tried ones
+ *
+ * Tries at most Attempts random words of the dictionary
+ */
+func (game *Game) balancedStartWord() string {
+	best, bestMoves := "", -1
+	for i := 0; i < game.startConf.Attempts; i++ {
+		word := game.dictionary.RandWordOfAS(game.AreaSize, game.rand)
+		if word == "" {
+			return ""
+		}
+
+		moves := len(NewSquare(game.AreaSize, game.dictionary, word).FindMoves(game.startConf.MinMoves))
+		if moves >= game.startConf.MinMoves {
+			return word
+		}
+		if moves > bestMoves {
+			best, bestMoves = word, moves
+		}
+	}
+
+	logger.Log.Warningf("Balanced start word isn't found in %d attempts, '%s' gives %d moves", game.startConf.Attempts, best, bestMoves)
+	return best
+}
@@ -0,0 +1,175 @@
+/**
+ * @file team.go
+ * @brief Team play
+ *
+ * Users are split into two teams by turn order, so turns alternate between teams.
+ * Teams are equal, so team game is played by even number of users.
+ * Score of team is the sum of scores of its members
+ */
+
+package game
+
+import (
+	// System
+	"fmt"
+	"sort"
+	"strings"
+
+	// Third-party
+
+	// Project
+	"github.com/BaldaGo/balda-go/db"
+)
+
+var teamNames = []string{"A", "B"} ///< Names of teams in order of their first move
+
+/**
+ * @brief Show or switch team play before game starts
+ * @param[in] args "on" or "off", if it is empty, shows current state
+ * @return Same values as Continue
+ */
+func (game *Game) teams(args []string) (bool, string, error) {
+	if len(args) == 0 || args[0] == "" {
+		if game.Teams {
+			return true, "Team play is on", nil
+		}
+		return true, "Team play is off", nil
+	}
+	if game.onStart {
+		return true, "Game already started, teams can't be changed", nil
+	}
+
+	switch args[0] {
+	case "on":
+		if game.MaxUsersPerGame%len(teamNames) != 0 {
+			return true, fmt.Sprintf("Team play needs even number of players, game is for %d", game.MaxUsersPerGame), nil
+		}
+		game.Teams = true
+	case "off":
+		game.Teams = false
+	default:
+		return true, "Not correct command, use: teams on|off", nil
+	}
+	return true, fmt.Sprintf("Team play is %s", args[0]), nil
+}
+
+/**
+ * @brief Split users into teams by turn order
+ * @return err Error if users can't be split into equal teams
+ */
+func (game *Game) assignTeams() error {
+	game.team = make(map[string]string)
+	if !game.Teams {
+		return nil
+	}
+	if len(game.users)%len(teamNames) != 0 {
+		return fmt.Errorf("Team play needs even number of players, game has %d", len(game.users))
+	}
+	for i, u := range game.users {
+		game.team[u] = teamNames[i%len(teamNames)]
+	}
+	return nil
+}
+
+/**
+ * @brief Team of user
+ * @param[in] user User's login
+ * @return team Name of team, empty string if game is played without teams
+ */
+func (game *Game) TeamOf(user string) string {
+	return game.team[user]
+}
+
+/**
+ * @brief Members of team
+ * @param[in] team Name of team
+ * @return users Logins of members in turn order
+ */
+func (game *Game) TeamMembers(team string) []string {
+	var users []string
+	for _, u := range game.users {
+		if game.team[u] == team {
+			users = append(users, u)
+		}
+	}
+	return users
+}
+
+/**
+ * @brief Scores of teams
+ * @return scores Score by name of team
+ */
+func (game *Game) teamScores() map[string]int {
+	scores := make(map[string]int)
+	for _, t := range teamNames {
+		scores[t] = 0
+	}
+	for u, sc := range game.scoreMap {
+		scores[game.team[u]] += sc
+	}
+	return scores
+}
+
+/**
+ * @brief Scores of teams and their members
+ */
+func (game *Game) teamScore() string {
+	scores := game.teamScores()
+	var lines []string
+	for _, t := range teamNames {
+		var members []string
+		for _, u := range game.TeamMembers(t) {
+			members = append(members, fmt.Sprintf("%s %d", u, game.scoreMap[u]))
+		}
+		lines = append(lines, fmt.Sprintf("Team %s : %d (%s)", t, scores[t], strings.Join(members, ", ")))
+	}
+	return strings.Join(lines, "\n\r")
+}
+
+/**
+ * @brief Final standings of teams
+ * @return str Teams sorted by score, one per line
+ */
+func (game *Game) teamStandings() string {
+	scores := game.teamScores()
+	teams := make([]string, len(teamNames))
+	copy(teams, teamNames)
+	sort.SliceStable(teams, func(i, j int) bool {
+		return scores[teams[i]] > scores[teams[j]]
+	})
+
+	lines := []string{"Teams:"}
+	for i, t := range teams {
+		lines = append(lines, fmt.Sprintf("%d. Team %s (%s) : %d", i+1, t, strings.Join(game.TeamMembers(t), ", "), scores[t]))
+	}
+	return strings.Join(lines, "\n\r")
+}
+
+/**
+ * @brief Result of the game for database
+ * @return result Scores, teams and winners
+ */
+func (game *Game) result() db.GameResult {
+	res := db.GameResult{Scores: game.scoreMap, Teams: game.team}
+	if !game.Teams {
+		if winner := game.winner(); winner != "" {
+			res.Winners = []string{winner}
+		}
+		return res
+	}
+
+	scores := game.teamScores()
+	hs := -1
+	for _, t := range teamNames {
+		if scores[t] > hs {
+			hs = scores[t]
+			res.WinnerTeam = t
+		} else if scores[t] == hs {
+			res.WinnerTeam = ""
+		}
+	}
+	if res.WinnerTeam != "" {
+		res.Winners = game.TeamMembers(res.WinnerTeam)
+	}
+	return res
+}
@@ -0,0 +1,813 @@
+/**
+ * @file tournament.go
+ * @brief Tournaments
+ *
+ * Tournaments are stored in database. Every round players are paired (round robin or Swiss),
+ * every pair plays ranked grid game in session reserved for them. When all games of the round
+ * are over, the next round is paired. Win gives 1 point, draw gives half of point, bye gives 1 point.
+ * Player, who doesn't join reserved session in time or leaves it, loses by forfeit.
+ * Admin can set result of any unfinished game of the current round
+ */
+
+package game
+
+import (
+	// System
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	// Third-party
+
+	// Project
+	"github.com/BaldaGo/balda-go/conf"
+	"github.com/BaldaGo/balda-go/db"
+	"github.com/BaldaGo/balda-go/logger"
+)
+
+/**
+ * @brief enum of tournament formats
+ */
+const (
+	FormatRoundRobin = "roundrobin" ///< Every player plays with every other player once
+	FormatSwiss      = "swiss"      ///< Players with equal points play with each other, pairs don't repeat
+)
+
+/**
+ * @brief enum of tournament statuses
+ */
+const (
+	TournamentRegistration = "registration" ///< Players join tournament
+	TournamentRunning      = "running"      ///< Rounds are played
+	TournamentFinished     = "finished"     ///< All rounds are played
+)
+
+/**
+ * @brief enum of results of tournament game set by admin, they are results of the given player
+ */
+const (
+	ResultWin     = "win"     ///< Player wins
+	ResultDraw    = "draw"    ///< Game is a draw
+	ResultLoss    = "loss"    ///< Player loses
+	ResultForfeit = "forfeit" ///< Player loses by forfeit
+)
+
+var formats = []string{FormatRoundRobin, FormatSwiss} ///< All tournament formats
+
+var results = []string{ResultWin, ResultDraw, ResultLoss, ResultForfeit} ///< All results set by admin
+
+var setupCommands = []string{"lang", "rules", "mode", "teams", "practice"} ///< Commands, which change rules of session
+
+/**
+ * @brief Callback, which is called when game is over
+ * @param[in] gameID Id of the game in database
+ * @param[in] result Final statistics of the game
+ * @return msg Message added to final standings, may be empty
+ */
+type FinishHandler func(gameID uint, result db.GameResult) string
+
+/**
+ * @class Tournaments
+ * @brief Class, provides tournaments
+ */
+type Tournaments struct {
+	cfg        conf.GameConf ///< Configuration of games
+	mu         sync.Mutex    ///< Lock of tournaments, games of the same round can finish at the same time
+	NoShowTime time.Duration ///< Time to join reserved session, players who didn't join lose by forfeit
+}
+
+/**
+ * @brief Create manager of tournaments
+ * @param[in] cfg Configuration of games, language of tournament games is taken from it
+ */
+func NewTournaments(cfg conf.GameConf) *Tournaments {
+	return &Tournaments{cfg: cfg, NoShowTime: cfg.NoShowTime * time.Minute}
+}
+
+/**
+ * @brief Create tournament open for registration
+ * @param[in] name Name of tournament
+ * @param[in] format Format of tournament (roundrobin, swiss)
+ * @param[in] ruleset Ruleset of games
+ * @param[in] rounds Number of rounds of Swiss tournament, 0 to choose it by number of players
+ * @return msg Message for admin
+ * @return err Error if database failed
+ */
+func (t *Tournaments) Create(name string, format string, ruleset string, rounds int) (string, error) {
+	if !contains(formats, format) {
+		return fmt.Sprintf("Unknown format '%s'. Available: %s", format, strings.Join(formats, ", ")), nil
+	}
+	if rounds < 0 {
+		return "Not correct number of rounds", nil
+	}
+	g := &Game{}
+	if err := g.setDictionary(t.cfg.Language, ruleset); err != nil {
+		return err.Error(), nil
+	}
+
+	tournament, err := db.CreateTournament(name, format, t.cfg.Language, ruleset, uint(rounds))
+	if err != nil {
+		return "", err
+	}
+	logger.Log.Infof("Tournament #%d '%s' is created", tournament.ID, name)
+	return fmt.Sprintf("Tournament #%d '%s' is created (%s, ruleset %s). Players join: tournament_join %d",
+		tournament.ID, name, format, ruleset, tournament.ID), nil
+}
+
+/**
+ * @brief Tournaments, which aren't finished
+ * @return msg Message for user
+ * @return err Error if database failed
+ */
+func (t *Tournaments) List() (string, error) {
+	tournaments, err := db.ActiveTournaments()
+	if err != nil {
+		return "", err
+	}
+	if len(tournaments) == 0 {
+		return "There are no tournaments", nil
+	}
+
+	lines := []string{"Tournaments:"}
+	for i := range tournaments {
+		lines = append(lines, summary(&tournaments[i]))
+	}
+	return strings.Join(lines, "\n\r"), nil
+}
+
+/**
+ * @brief Register user in tournament
+ * @param[in] id Id of tournament
+ * @param[in] user User's login
+ * @return msg Message for user
+ * @return err Error if database failed
+ */
+func (t *Tournaments) Join(id uint, user string) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tournament, players, _, err := db.TournamentByID(id)
+	if err != nil {
+		return fmt.Sprintf("Tournament #%d is not found", id), nil
+	}
+	if tournament.Status != TournamentRegistration {
+		return fmt.Sprintf("Registration in tournament #%d is closed", id), nil
+	}
+	for i := range players {
+		if players[i].User.Name == user {
+			return fmt.Sprintf("You are already in tournament #%d", id), nil
+		}
+	}
+
+	if _, err := db.AddTournamentPlayer(id, user); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("You joined tournament #%d '%s' (%d players)", id, tournament.Name, len(players)+1), nil
+}
+
+/**
+ * @brief Close registration and pair the first round
+ * @param[in] id Id of tournament
+ * @return msg Message for admin
+ * @return err Error if database failed
+ */
+func (t *Tournaments) Start(id uint) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tournament, players, games, err := db.TournamentByID(id)
+	if err != nil {
+		return fmt.Sprintf("Tournament #%d is not found", id), nil
+	}
+	if tournament.Status != TournamentRegistration {
+		return fmt.Sprintf("Tournament #%d already started", id), nil
+	}
+	if len(players) < 2 {
+		return "Tournament needs at least 2 players", nil
+	}
+
+	n := len(players)
+	switch {
+	case tournament.Format == FormatRoundRobin || tournament.Rounds > uint(n-1+n%2):
+		tournament.Rounds = uint(n - 1 + n%2)
+	case tournament.Rounds == 0:
+		tournament.Rounds = uint(math.Ceil(math.Log2(float64(n))))
+	}
+	tournament.Status = TournamentRunning
+
+	if _, err := nextRound(tournament, players, games); err != nil {
+		return "", err
+	}
+	logger.Log.Infof("Tournament #%d is started", id)
+	return fmt.Sprintf("Tournament #%d is started: %d players, %d rounds", id, n, tournament.Rounds), nil
+}
+
+/**
+ * @brief Standings and games of the current round
+ * @param[in] id Id of tournament
+ * @return msg Message for user
+ * @return err Error if database failed
+ */
+func (t *Tournaments) Show(id uint) (string, error) {
+	tournament, players, games, err := db.TournamentByID(id)
+	if err != nil {
+		return fmt.Sprintf("Tournament #%d is not found", id), nil
+	}
+
+	lines := []string{summary(tournament), standings(players)}
+	if tournament.Status == TournamentRunning {
+		lines = append(lines, fmt.Sprintf("Round %d:", tournament.Round))
+		for i := range games {
+			if games[i].Round == tournament.Round {
+				lines = append(lines, pairing(&games[i]))
+			}
+		}
+	}
+	return strings.Join(lines, "\n\r"), nil
+}
+
+/**
+ * @brief Tournament games of user, which wait for players in sessions
+ * @param[in] user User's login
+ * @return msg Message for user, empty if there are no games
+ * @return err Error if database failed
+ */
+func (t *Tournaments) Inbox(user string) (string, error) {
+	games, err := db.UserTournamentGames(user)
+	if err != nil {
+		return "", err
+	}
+	if len(games) == 0 {
+		return "", nil
+	}
+
+	lines := []string{"Your tournament games:"}
+	for i := range games {
+		lines = append(lines, fmt.Sprintf("#%d '%s', round %d: session %d",
+			games[i].TournamentID, games[i].Tournament.Name, games[i].Round, games[i].Session))
+	}
+	return strings.Join(lines, "\n\r"), nil
+}
+
+/**
+ * @brief Games of running tournaments, which wait for free session
+ * @return games Games with their tournaments and players or error if database failed
+ */
+func (t *Tournaments) Pending() ([]db.TournamentGame, error) {
+	return db.PendingTournamentGames()
+}
+
+/**
+ * @brief Save session, where tournament game is played
+ * @param[in] tg Tournament game
+ * @param[in] session Id of session
+ * @param[in] gameID Id of the game in database
+ * @return err Error if database failed
+ */
+func (t *Tournaments) Assign(tg db.TournamentGame, session int, gameID uint) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tg.Session = session
+	tg.GameID = gameID
+	return db.SaveTournamentGame(&tg)
+}
+
+/**
+ * @brief Free sessions of unfinished tournament games after restart of server
+ * @return err Error if database failed
+ */
+func (t *Tournaments) Restore() error {
+	return db.UnassignTournamentGames()
+}
+
+/**
+ * @brief Count result of tournament game and pair the next round if the current one is over
+ * @param[in] gameID Id of the game in database
+ * @param[in] result Final statistics of the game
+ * @return msg Message for players, empty if game isn't tournament game
+ */
+func (t *Tournaments) Report(gameID uint, result db.GameResult) string {
+	msg, err := t.report(gameID, result)
+	if err != nil {
+		logger.Log.Critical(logger.Trace(err, "Tournament result isn't saved").Error())
+		return ""
+	}
+	return msg
+}
+
+/**
+ * @brief Count result of tournament game
+ * @param[in] gameID Id of the game in database
+ * @param[in] result Final statistics of the game
+ * @return msg Message for players
+ * @return err Error if database failed
+ */
+func (t *Tournaments) report(gameID uint, result db.GameResult) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tournament, players, games, tg, err := tournamentGame(gameID)
+	if err != nil || tg == nil {
+		return "", err
+	}
+
+	winnerID := uint(0)
+	if len(result.Winners) == 1 {
+		switch result.Winners[0] {
+		case tg.Player1.Name:
+			winnerID = tg.Player1ID
+		case tg.Player2.Name:
+			winnerID = tg.Player2ID
+		}
+	}
+	return saveResult(tournament, players, games, tg, winnerID, result.Scores)
+}
+
+/**
+ * @brief Count forfeit of players, who didn't join tournament game in time or left it
+ * @param[in] gameID Id of the game in database
+ * @param[in] losers Players, who lose by forfeit
+ * @return msg Message for players, empty if game isn't unfinished tournament game
+ * @return err Error if database failed
+ *
+ * If both players lose by forfeit, nobody gets points
+ */
+func (t *Tournaments) Forfeit(gameID uint, losers []string) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tournament, players, games, tg, err := tournamentGame(gameID)
+	if err != nil || tg == nil {
+		return "", err
+	}
+
+	tg.Forfeit = true
+	winnerID := uint(0)
+	switch {
+	case len(losers) == 1 && losers[0] == tg.Player1.Name:
+		winnerID = tg.Player2ID
+	case len(losers) == 1 && losers[0] == tg.Player2.Name:
+		winnerID = tg.Player1ID
+	}
+	logger.Log.Infof("Tournament #%d: %s lose by forfeit", tournament.ID, strings.Join(losers, ", "))
+	return saveResult(tournament, players, games, tg, winnerID, nil)
+}
+
+/**
+ * @brief Set result of unfinished game of the current round by admin
+ * @param[in] id Id of tournament
+ * @param[in] player Login of player
+ * @param[in] result Result of player (win, draw, loss, forfeit)
+ * @return gameID Id of the game in database, its session must be freed (0 if game has no session)
+ * @return msg Message for admin
+ * @return err Error if database failed
+ */
+func (t *Tournaments) Result(id uint, player string, result string) (uint, string, error) {
+	if !contains(results, result) {
+		return 0, fmt.Sprintf("Unknown result '%s'. Available: %s", result, strings.Join(results, ", ")), nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tournament, players, games, err := db.TournamentByID(id)
+	if err != nil {
+		return 0, fmt.Sprintf("Tournament #%d is not found", id), nil
+	}
+	if tournament.Status != TournamentRunning {
+		return 0, fmt.Sprintf("Tournament #%d isn't running", id), nil
+	}
+
+	var tg *db.TournamentGame
+	for i := range games {
+		if games[i].Round == tournament.Round && !games[i].Finished && (games[i].Player1.Name == player || games[i].Player2.Name == player) {
+			tg = &games[i]
+		}
+	}
+	if tg == nil {
+		return 0, fmt.Sprintf("%s has no unfinished game in round %d", player, tournament.Round), nil
+	}
+
+	self, opponent := tg.Player1ID, tg.Player2ID
+	if tg.Player2.Name == player {
+		self, opponent = opponent, self
+	}
+	winnerID := uint(0)
+	switch result {
+	case ResultWin:
+		winnerID = self
+	case ResultLoss, ResultForfeit:
+		winnerID = opponent
+	}
+	tg.Forfeit = result == ResultForfeit
+
+	gameID := tg.GameID
+	msg, err := saveResult(tournament, players, games, tg, winnerID, nil)
+	return gameID, msg, err
+}
+
+/**
+ * @brief Tournament of game played in game session
+ * @param[in] gameID Id of the game in database
+ * @return tournament Tournament
+ * @return players Players of tournament
+ * @return games Games of tournament
+ * @return tg Game from games, nil if it isn't unfinished tournament game
+ * @return err Error if database failed
+ */
+func tournamentGame(gameID uint) (*db.Tournament, []db.TournamentPlayer, []db.TournamentGame, *db.TournamentGame, error) {
+	found, err := db.TournamentGameByGameID(gameID)
+	if err != nil || found == nil {
+		return nil, nil, nil, nil, err
+	}
+	tournament, players, games, err := db.TournamentByID(found.TournamentID)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	for i := range games {
+		if games[i].ID == found.ID && !games[i].Finished {
+			return tournament, players, games, &games[i], nil
+		}
+	}
+	return nil, nil, nil, nil, nil
+}
+
+/**
+ * @brief Save result of tournament game and pair the next round if the current one is over
+ * @param[in] tournament Tournament
+ * @param[in] players Players of tournament
+ * @param[in] games Games of tournament
+ * @param[in] tg Finished game from games
+ * @param[in] winnerID Id of winner, 0 if it is a draw or both players lose by forfeit
+ * @param[in] scores Scores of players in game
+ * @return msg Message for players
+ * @return err Error if database failed
+ */
+func saveResult(tournament *db.Tournament, players []db.TournamentPlayer, games []db.TournamentGame, tg *db.TournamentGame, winnerID uint, scores map[string]int) (string, error) {
+	tg.Finished = true
+	tg.WinnerID = winnerID
+	for i := range players {
+		p := &players[i]
+		if p.UserID != tg.Player1ID && p.UserID != tg.Player2ID {
+			continue
+		}
+		p.Scores += uint(scores[p.User.Name])
+		switch {
+		case tg.WinnerID == p.UserID:
+			p.Points++
+		case tg.WinnerID == 0 && !tg.Forfeit:
+			p.Points += 0.5
+		}
+	}
+	if err := db.SaveTournament(tournament, players, []db.TournamentGame{*tg}); err != nil {
+		return "", err
+	}
+
+	for i := range games {
+		if games[i].Round == tournament.Round && !games[i].Finished {
+			return fmt.Sprintf("Tournament #%d: result is saved, round %d goes on", tournament.ID, tournament.Round), nil
+		}
+	}
+
+	if tournament.Round < tournament.Rounds {
+		paired, err := nextRound(tournament, players, games)
+		if err != nil {
+			return "", err
+		}
+		if paired {
+			return fmt.Sprintf("Tournament #%d: round %d is paired, see: tournament_show %d", tournament.ID, tournament.Round, tournament.ID), nil
+		}
+		logger.Log.Infof("Tournament #%d: all pairs have already met, tournament is over after round %d", tournament.ID, tournament.Round)
+		tournament.Rounds = tournament.Round
+	}
+
+	tournament.Status = TournamentFinished
+	if err := db.SaveTournament(tournament, nil, nil); err != nil {
+		return "", err
+	}
+	logger.Log.Infof("Tournament #%d is finished", tournament.ID)
+	return strings.Join([]string{fmt.Sprintf("Tournament #%d '%s' is finished!", tournament.ID, tournament.Name), standings(players)}, "\n\r"), nil
+}
+
+/**
+ * @brief Pair the next round and save it
+ * @param[in] tournament Tournament
+ * @param[in] players Players with their points, players with byes get points
+ * @param[in] games Games of previous rounds
+ * @return paired False if Swiss round can't be paired without repeated pairs
+ * @return err Error if database failed
+ */
+func nextRound(tournament *db.Tournament, players []db.TournamentPlayer, games []db.TournamentGame) (bool, error) {
+	ids := make(map[string]uint)
+	for i := range players {
+		ids[players[i].User.Name] = players[i].UserID
+	}
+
+	var pairs [][2]string
+	if tournament.Format == FormatRoundRobin {
+		names := []string{}
+		for i := range players {
+			names = append(names, players[i].User.Name)
+		}
+		pairs = roundRobinPairs(names, int(tournament.Round))
+	} else {
+		var ok bool
+		if pairs, ok = swissPairs(players, games); !ok {
+			return false, nil
+		}
+	}
+
+	tournament.Round++
+	round := []db.TournamentGame{}
+	for _, pair := range pairs {
+		tg := db.TournamentGame{TournamentID: tournament.ID, Round: tournament.Round, Player1ID: ids[pair[0]], Player2ID: ids[pair[1]]}
+		if pair[1] == "" {
+			tg.Finished = true
+			tg.WinnerID = tg.Player1ID
+			for i := range players {
+				if players[i].UserID == tg.Player1ID {
+					players[i].Points++
+					players[i].Byes++
+				}
+			}
+		}
+		round = append(round, tg)
+	}
+	logger.Log.Infof("Tournament #%d: round %d is paired", tournament.ID, tournament.Round)
+	return true, db.SaveTournament(tournament, players, round)
+}
+
+/**
+ * @brief Pairs of round robin tournament by circle method
+ * @param[in] names Players in order of registration
+ * @param[in] round Number of round from 0
+ * @return pairs Pairs of players, the second player is empty for bye
+ */
+func roundRobinPairs(names []string, round int) [][2]string {
+	circle := append([]string{}, names...)
+	if len(circle)%2 == 1 {
+		circle = append(circle, "")
+	}
+
+	n := len(circle)
+	rotated := []string{circle[0]}
+	for i := 0; i < n-1; i++ {
+		rotated = append(rotated, circle[1+(i+n-1-round%(n-1))%(n-1)])
+	}
+
+	pairs := [][2]string{}
+	for i := 0; i < n/2; i++ {
+		a, b := rotated[i], rotated[n-1-i]
+		if a == "" {
+			a, b = b, a
+		}
+		pairs = append(pairs, [2]string{a, b})
+	}
+	return pairs
+}
+
+/**
+ * @brief Pairs of Swiss tournament
+ * @param[in] players Players with their points
+ * @param[in] games Games of previous rounds
+ * @return pairs Pairs of players, the second player is empty for bye
+ * @return ok False if players can't be paired without repeated pairs
+ *
+ * Players are sorted by points, every player is paired with the highest player
+ * he hasn't played with yet, so that the rest can be paired too.
+ * Bye is given to the lowest player without byes, if the rest can be paired
+ */
+func swissPairs(players []db.TournamentPlayer, games []db.TournamentGame) ([][2]string, bool) {
+	sorted := ranking(players)
+	met := make(map[[2]uint]bool)
+	for i := range games {
+		met[[2]uint{games[i].Player1ID, games[i].Player2ID}] = true
+		met[[2]uint{games[i].Player2ID, games[i].Player1ID}] = true
+	}
+
+	if len(sorted)%2 == 0 {
+		return matchPlayers(sorted, met)
+	}
+
+	candidates := []int{}
+	for i := len(sorted) - 1; i >= 0; i-- {
+		if sorted[i].Byes == 0 {
+			candidates = append(candidates, i)
+		}
+	}
+	for i := len(sorted) - 1; i >= 0; i-- {
+		if sorted[i].Byes > 0 {
+			candidates = append(candidates, i)
+		}
+	}
+	for _, bye := range candidates {
+		rest := append(append([]db.TournamentPlayer{}, sorted[:bye]...), sorted[bye+1:]...)
+		if pairs, ok := matchPlayers(rest, met); ok {
+			return append([][2]string{{sorted[bye].User.Name, ""}}, pairs...), true
+		}
+	}
+	return nil, false
+}
+
+/**
+ * @brief Pair sorted players, who haven't met yet
+ * @param[in] sorted Players sorted by points, number of them is even
+ * @param[in] met Pairs of ids of players, who have met
+ * @return pairs Pairs of players
+ * @return ok False if players can't be paired
+ *
+ * The first player takes the highest possible opponent, pairing goes back if the rest can't be paired
+ */
+func matchPlayers(sorted []db.TournamentPlayer, met map[[2]uint]bool) ([][2]string, bool) {
+	if len(sorted) == 0 {
+		return [][2]string{}, true
+	}
+
+	for j := 1; j < len(sorted); j++ {
+		if met[[2]uint{sorted[0].UserID, sorted[j].UserID}] {
+			continue
+		}
+		rest := append(append([]db.TournamentPlayer{}, sorted[1:j]...), sorted[j+1:]...)
+		if pairs, ok := matchPlayers(rest, met); ok {
+			return append([][2]string{{sorted[0].User.Name, sorted[j].User.Name}}, pairs...), true
+		}
+	}
+	return nil, false
+}
+
+/**
+ * @brief Players sorted by points, then by scores
+ * @param[in] players Players of tournament
+ */
+func ranking(players []db.TournamentPlayer) []db.TournamentPlayer {
+	sorted := append([]db.TournamentPlayer{}, players...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Points != sorted[j].Points {
+			return sorted[i].Points > sorted[j].Points
+		}
+		return sorted[i].Scores > sorted[j].Scores
+	})
+	return sorted
+}
+
+/**
+ * @brief Standings of tournament
+ * @param[in] players Players of tournament
+ */
+func standings(players []db.TournamentPlayer) string {
+	lines := []string{"Standings:"}
+	for i, p := range ranking(players) {
+		lines = append(lines, fmt.Sprintf("%d. %s : %g points, %d scores", i+1, p.User.Name, p.Points, p.Scores))
+	}
+	return strings.Join(lines, "\n\r")
+}
+
+/**
+ * @brief Short information about tournament
+ * @param[in] tournament Tournament
+ */
+func summary(tournament *db.Tournament) string {
+	str := fmt.Sprintf("#%d '%s': %s, ruleset %s, %s", tournament.ID, tournament.Name, tournament.Format, tournament.Ruleset, tournament.Status)
+	if tournament.Status == TournamentRunning {
+		str += fmt.Sprintf(", round %d/%d", tournament.Round, tournament.Rounds)
+	}
+	return str
+}
+
+/**
+ * @brief Game of tournament round and its state
+ * @param[in] tg Tournament game
+ */
+func pairing(tg *db.TournamentGame) string {
+	switch {
+	case tg.Player2ID == 0:
+		return fmt.Sprintf("%s: bye", tg.Player1.Name)
+	case !tg.Finished && tg.GameID == 0:
+		return fmt.Sprintf("%s - %s: waits for free session", tg.Player1.Name, tg.Player2.Name)
+	case !tg.Finished:
+		return fmt.Sprintf("%s - %s: session %d", tg.Player1.Name, tg.Player2.Name, tg.Session)
+	}
+
+	forfeit := ""
+	if tg.Forfeit {
+		forfeit = " (forfeit)"
+	}
+	switch {
+	case tg.WinnerID == tg.Player1ID:
+		return fmt.Sprintf("%s - %s: 1-0%s", tg.Player1.Name, tg.Player2.Name, forfeit)
+	case tg.WinnerID == tg.Player2ID:
+		return fmt.Sprintf("%s - %s: 0-1%s", tg.Player1.Name, tg.Player2.Name, forfeit)
+	case tg.Forfeit:
+		return fmt.Sprintf("%s - %s: 0-0%s", tg.Player1.Name, tg.Player2.Name, forfeit)
+	}
+	return fmt.Sprintf("%s - %s: draw", tg.Player1.Name, tg.Player2.Name)
+}
+
+/**
+ * @class reservation
+ * @brief Class, provides session reserved for tournament game
+ *
+ * Rules of session are saved and restored, when session is cleared
+ */
+type reservation struct {
+	players  []string ///< Players of tournament game
+	max      int      ///< Maximum number of users in session
+	mode     string   ///< Mode of session
+	teams    bool     ///< Team play of session
+	ranked   bool     ///< Flag if session is ranked
+	language string   ///< Language of session
+	ruleset  string   ///< Ruleset of session
+}
+
+/**
+ * @brief Reserve free session for tournament game
+ * @param[in] players Players of tournament game
+ * @param[in] language Language of tournament
+ * @param[in] ruleset Ruleset of tournament
+ * @return err Error if session isn't free or dictionary is unknown
+ *
+ * Tournament game is ranked grid game without teams
+ */
+func (game *Game) Reserve(players []string, language string, ruleset string) error {
+	game.mu.Lock()
+	defer game.mu.Unlock()
+
+	if !game.Free() {
+		return errors.New("Session isn't free")
+	}
+
+	r := &reservation{
+		players:  players,
+		max:      game.MaxUsersPerGame,
+		mode:     game.Mode,
+		teams:    game.Teams,
+		ranked:   game.Ranked,
+		language: game.Language,
+		ruleset:  game.Ruleset,
+	}
+	if err := game.setDictionary(language, ruleset); err != nil {
+		return err
+	}
+
+	game.reservation = r
+	game.MaxUsersPerGame = len(players)
+	game.Mode = ModeGrid
+	game.Teams = false
+	game.Ranked = true
+	return nil
+}
+
+/**
+ * @brief Predicate, check if session has no users and isn't reserved
+ */
+func (game *Game) Free() bool {
+	return len(game.users) == 0 && !game.onStart && !game.finished && game.reservation == nil
+}
+
+/**
+ * @brief Predicate, check if session is reserved for tournament game
+ */
+func (game *Game) Reserved() bool {
+	return game.reservation != nil
+}
+
+/**
+ * @brief Players of tournament game, for whom session is reserved
+ */
+func (game *Game) ReservedPlayers() []string {
+	if game.reservation == nil {
+		return nil
+	}
+	return game.reservation.players
+}
+
+/**
+ * @brief Id of the game in database
+ */
+func (game *Game) GameID() uint {
+	return game.dbGameID
+}
+
+/**
+ * @brief Restore rules of session after tournament game
+ */
+func (game *Game) release() {
+	r := game.reservation
+	if r == nil {
+		return
+	}
+	if err := game.setDictionary(r.language, r.ruleset); err != nil {
+		logger.Log.Warning(err.Error())
+	}
+	game.MaxUsersPerGame = r.max
+	game.Mode = r.mode
+	game.Teams = r.teams
+	game.Ranked = r.ranked
+	game.reservation = nil
+}
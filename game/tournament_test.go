@@ -0,0 +1,164 @@
+/**
+ * @file tournament_test.go
+ * @brief Tests of tournament pairings
+ */
+
+package game
+
+import (
+	// System
+	"fmt"
+	"math"
+	"testing"
+
+	// Third-party
+
+	// Project
+	"github.com/BaldaGo/balda-go/db"
+)
+
+/**
+ * @brief Players named p1, p2, ... with ids 1, 2, ...
+ * @param[in] n Number of players
+ */
+func testPlayers(n int) []db.TournamentPlayer {
+	players := make([]db.TournamentPlayer, n)
+	for i := range players {
+		players[i].UserID = uint(i + 1)
+		players[i].User.ID = uint(i + 1)
+		players[i].User.Name = fmt.Sprintf("p%d", i+1)
+	}
+	return players
+}
+
+/**
+ * @brief Every player plays with every other player once and plays once in every round
+ */
+func TestRoundRobinPairs(t *testing.T) {
+	for n := 2; n <= 9; n++ {
+		names := []string{}
+		for _, p := range testPlayers(n) {
+			names = append(names, p.User.Name)
+		}
+
+		met := make(map[[2]string]int)
+		rounds := n - 1 + n%2
+		for round := 0; round < rounds; round++ {
+			played := make(map[string]bool)
+			for _, pair := range roundRobinPairs(names, round) {
+				if pair[0] == "" {
+					t.Errorf("n=%d, round %d: bye is the first player of pair", n, round)
+				}
+				for _, name := range pair {
+					if name != "" && played[name] {
+						t.Errorf("n=%d, round %d: %s plays twice", n, round, name)
+					}
+					played[name] = true
+				}
+				if pair[1] != "" {
+					met[[2]string{pair[0], pair[1]}]++
+					met[[2]string{pair[1], pair[0]}]++
+				}
+			}
+			if len(played) != n+n%2 {
+				t.Errorf("n=%d, round %d: %d players are paired", n, round, len(played))
+			}
+		}
+
+		for i := range names {
+			for j := range names {
+				if i != j && met[[2]string{names[i], names[j]}] != 1 {
+					t.Errorf("n=%d: %s and %s met %d times", n, names[i], names[j], met[[2]string{names[i], names[j]}])
+				}
+			}
+		}
+	}
+}
+
+/**
+ * @brief Play Swiss tournament, the first player of pair wins
+ * @param[in] t Test
+ * @param[in] n Number of players
+ * @param[in] rounds Number of rounds
+ * @return paired Number of paired rounds
+ */
+func playSwiss(t *testing.T, n int, rounds int) int {
+	players := testPlayers(n)
+	ids := make(map[string]int)
+	for i := range players {
+		ids[players[i].User.Name] = i
+	}
+
+	games := []db.TournamentGame{}
+	for round := 0; round < rounds; round++ {
+		pairs, ok := swissPairs(players, games)
+		if !ok {
+			return round
+		}
+
+		played := make(map[string]bool)
+		for _, pair := range pairs {
+			for _, name := range pair {
+				if name != "" && played[name] {
+					t.Errorf("n=%d, round %d: %s plays twice", n, round, name)
+				}
+				played[name] = true
+			}
+
+			p1 := &players[ids[pair[0]]]
+			p1.Points++
+			if pair[1] == "" {
+				p1.Byes++
+				continue
+			}
+			p2 := &players[ids[pair[1]]]
+			for _, g := range games {
+				if (g.Player1ID == p1.UserID && g.Player2ID == p2.UserID) || (g.Player1ID == p2.UserID && g.Player2ID == p1.UserID) {
+					t.Errorf("n=%d, round %d: %s and %s meet again", n, round, pair[0], pair[1])
+				}
+			}
+			games = append(games, db.TournamentGame{Player1ID: p1.UserID, Player2ID: p2.UserID})
+		}
+		if len(played) != n+n%2 {
+			t.Errorf("n=%d, round %d: %d players are paired", n, round, len(played))
+		}
+	}
+	return rounds
+}
+
+/**
+ * @brief Pairs of Swiss tournament don't repeat
+ */
+func TestSwissPairs(t *testing.T) {
+	for n := 2; n <= 12; n++ {
+		rounds := int(math.Ceil(math.Log2(float64(n))))
+		if paired := playSwiss(t, n, rounds); paired != rounds {
+			t.Errorf("n=%d: only %d of %d rounds are paired", n, paired, rounds)
+		}
+		playSwiss(t, n, n-1+n%2)
+	}
+}
+
+/**
+ * @brief Swiss round isn't paired, if all pairs have already met
+ */
+func TestSwissPairsExhausted(t *testing.T) {
+	if paired := playSwiss(t, 4, 5); paired != 3 {
+		t.Errorf("4 players: %d rounds are paired, expected 3", paired)
+	}
+}
+
+/**
+ * @brief Bye is given to the lowest player without byes
+ */
+func TestSwissBye(t *testing.T) {
+	players := testPlayers(3)
+	players[0].Points = 1
+	players[1].Points = 1
+	players[2].Byes = 1
+
+	pairs, ok := swissPairs(players, nil)
+	if !ok || pairs[0] != [2]string{"p2", ""} {
+		t.Errorf("Bye is given to %v", pairs)
+	}
+}
@@ -0,0 +1,224 @@
+/**
+ * @file training.go
+ * @brief Training
+ *
+ * Single-player puzzles, which aren't saved into database:
+ * user takes a puzzle of chosen difficulty and tries moves until the best one is found
+ */
+
+package game
+
+import (
+	// System
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	// Third-party
+
+	// Project
+	"github.com/BaldaGo/balda-go/conf"
+	"github.com/BaldaGo/balda-go/logger"
+)
+
+/**
+ * @class Training
+ * @brief Class, provides training puzzles of users
+ */
+type Training struct {
+	cfg     conf.GameConf      ///< Configuration of games
+	puzzles []Puzzle           ///< Puzzles from file, puzzles are generated if it is empty
+	daily   *Daily             ///< Daily puzzles, which aren't given in training
+	mu      sync.Mutex         ///< Lock of current puzzles and random generator
+	rand    *rand.Rand         ///< Random generator
+	current map[string]*Puzzle ///< Current puzzle by user
+}
+
+/**
+ * @brief Create manager of training puzzles
+ * @param[in] cfg Configuration of games
+ * @param[in] puzzles Puzzles from file (optional)
+ * @param[in] daily Daily puzzles (optional)
+ */
+func NewTraining(cfg conf.GameConf, puzzles []Puzzle, daily *Daily) *Training {
+	return &Training{
+		cfg:     cfg,
+		puzzles: puzzles,
+		daily:   daily,
+		rand:    rand.New(rand.NewSource(time.Now().UnixNano())),
+		current: make(map[string]*Puzzle),
+	}
+}
+
+/**
+ * @brief Give new puzzle to user
+ * @param[in] user User's login
+ * @param[in] difficulty Difficulty of puzzle, any difficulty if it is empty
+ * @return msg Message for user
+ * @return err Error if puzzle can't be generated
+ *
+ * Puzzles of file, which aren't solvable with current dictionary or are offensive, are skipped.
+ * Puzzle of the day isn't given
+ */
+func (t *Training) Next(user string, difficulty string) (string, error) {
+	if difficulty != "" && !IsDifficulty(difficulty) {
+		return fmt.Sprintf("Unknown difficulty '%s'. Available: %s", difficulty, strings.Join(difficulties, ", ")), nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var suitable []int
+	for i := range t.puzzles {
+		if difficulty == "" || t.puzzles[i].Difficulty == difficulty {
+			suitable = append(suitable, i)
+		}
+	}
+
+	g, err := t.game()
+	if err != nil {
+		return "", err
+	}
+	delete(t.current, user)
+	for len(suitable) > 0 {
+		i := t.rand.Intn(len(suitable))
+		if p := &t.puzzles[suitable[i]]; p.solvable(g) && !t.isDaily(p) {
+			t.current[user] = p
+			break
+		}
+		suitable = append(suitable[:i], suitable[i+1:]...)
+	}
+
+	if _, ok := t.current[user]; !ok {
+		gen, err := NewGenerator(t.cfg, t.rand.Int63(), MeasureLength)
+		if err != nil {
+			return "", err
+		}
+		puzzles, err := gen.Generate(1, difficulty)
+		if err != nil {
+			logger.Log.Warning(err.Error())
+			return "Puzzle isn't found, try again or choose another difficulty", nil
+		}
+		if t.isDaily(&puzzles[0]) {
+			return "Puzzle isn't found, try again or choose another difficulty", nil
+		}
+		t.current[user] = &puzzles[0]
+	}
+
+	return t.show(user)
+}
+
+/**
+ * @brief Show current puzzle of user
+ * @param[in] user User's login
+ * @return msg Message for user
+ * @return err Error if dictionary is unknown
+ */
+func (t *Training) Show(user string) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.show(user)
+}
+
+/**
+ * @brief Current puzzle of user, lock must be taken
+ * @param[in] user User's login
+ */
+func (t *Training) show(user string) (string, error) {
+	p, ok := t.current[user]
+	if !ok {
+		return "You don't have a puzzle, type: train [easy|medium|hard]", nil
+	}
+	g, err := t.game()
+	if err != nil {
+		return "", err
+	}
+
+	square := p.square(g)
+	return strings.Join([]string{
+		fmt.Sprintf("Puzzle (%s): find the only move, which gives %d", p.Difficulty, p.Score()),
+		square.StrPrintArea(),
+		"Words: " + strings.Join(p.Words, ", "),
+		"Type: train <x> <y> <letter> <word>, or 'train answer' to give up",
+	}, "\n\r"), nil
+}
+
+/**
+ * @brief Check move of user in current puzzle
+ * @param[in] user User's login
+ * @param[in] x Horisontal coordinate of the new letter (column)
+ * @param[in] y Vertical coordinate of the new letter (row)
+ * @param[in] letter New letter
+ * @param[in] word Word made with the new letter
+ * @return msg Message for user
+ * @return err Error if dictionary is unknown
+ *
+ * Puzzle is finished, when the best move is found
+ */
+func (t *Training) Try(user string, x int, y int, letter string, word string) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	p, ok := t.current[user]
+	if !ok {
+		return "You don't have a puzzle, type: train [easy|medium|hard]", nil
+	}
+	g, err := t.game()
+	if err != nil {
+		return "", err
+	}
+
+	score, word, err := p.check(g, x, y, letter, word)
+	switch {
+	case err != nil:
+		return err.Error(), nil
+	case score == 0:
+		return fmt.Sprintf("You can't make word '%s' there. Try again", word), nil
+	case score < p.Score():
+		return fmt.Sprintf("'%s' gives %d, the best move gives %d. Try again", word, score, p.Score()), nil
+	}
+
+	delete(t.current, user)
+	return fmt.Sprintf("Solved! '%s' gives %d. Type 'train' for the next puzzle", word, score), nil
+}
+
+/**
+ * @brief Give up current puzzle and show its answer
+ * @param[in] user User's login
+ * @return msg Message for user
+ */
+func (t *Training) Answer(user string) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	p, ok := t.current[user]
+	if !ok {
+		return "You don't have a puzzle, type: train [easy|medium|hard]"
+	}
+	delete(t.current, user)
+	if t.isDaily(p) {
+		return "It is the puzzle of the day now, its answer isn't shown. Type 'daily' to solve it"
+	}
+	return fmt.Sprintf("Answer: '%c' at (%d, %d), word '%s'", p.Answer.Letter, p.Answer.X, p.Answer.Y, p.Answer.Word)
+}
+
+/**
+ * @brief Game with current dictionary and size of area
+ */
+func (t *Training) game() (*Game, error) {
+	g := &Game{AreaSize: t.cfg.AreaSize}
+	if err := g.setDictionary(t.cfg.Language, t.cfg.Ruleset); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+/**
+ * @brief Predicate, check if puzzle is the puzzle of the day
+ * @param[in] p Puzzle
+ */
+func (t *Training) isDaily(p *Puzzle) bool {
+	return t.daily != nil && t.daily.Is(p)
+}
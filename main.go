@@ -12,13 +12,18 @@ package main
 
 import (
 	// System
+	"errors"
+	"fmt"
+	"time"
 
 	// Third-party
 
 	// Project
 	"github.com/BaldaGo/balda-go/conf"
 	"github.com/BaldaGo/balda-go/db"
+	"github.com/BaldaGo/balda-go/dict"
 	"github.com/BaldaGo/balda-go/flags"
+	"github.com/BaldaGo/balda-go/game"
 	"github.com/BaldaGo/balda-go/logger"
 	"github.com/BaldaGo/balda-go/server"
 )
@@ -39,14 +44,30 @@ func main() {
 
 	logger.Init(config.Logger)
 
+	if flags.Command == "compile-dict" {
+		if err := compileDict(config.Server, flags.CompileDict); err != nil {
+			panic(err)
+		}
+		return
+	}
+
+	if flags.Command == "generate-puzzles" {
+		if err := generatePuzzles(config, flags.GeneratePuzzles); err != nil {
+			panic(err)
+		}
+		return
+	}
+
 	server := server.New(config.Server)
 
 	if err := db.Init(config.Database); err != nil {
 		panic(err)
 	}
 
-	if err := db.LoadDictionary(config.Server.DictPath); err != nil {
-		panic(err)
+	for _, d := range config.Server.Dictionaries {
+		if err := db.LoadDictionary(d.Name, d.Path); err != nil {
+			panic(err)
+		}
 	}
 
 	if err = server.PreRun(config.Server); err != nil {
@@ -62,3 +83,89 @@ func main() {
 
 	logger.Log.Info("Server shutdowned")
 }
+
+/**
+ * @brief Compile dictionaries from configuration into binary files
+ * @param[in] cfg Server configuration with dictionaries
+ * @param[in] args Arguments of compile-dict command
+ * @return err Error if it occured
+ */
+func compileDict(cfg conf.ServerConf, args flags.CompileDictCommand) error {
+	if args.Output != "" && args.Name == "" && len(cfg.Dictionaries) > 1 {
+		return errors.New("Output path is given for several dictionaries, choose one with --name")
+	}
+
+	compiled := 0
+	for _, d := range cfg.Dictionaries {
+		if args.Name != "" && args.Name != d.Name {
+			continue
+		}
+
+		start := time.Now()
+		if err := dict.Compile(d, string(args.Output)); err != nil {
+			return logger.Trace(err, fmt.Sprintf("Can't compile dictionary '%s'", d.Name))
+		}
+		logger.Log.Infof("Dictionary '%s' compiled in %s", d.Name, time.Since(start))
+		compiled++
+	}
+
+	if compiled == 0 {
+		return errors.New(fmt.Sprintf("Dictionary '%s' is not configured", args.Name))
+	}
+	return nil
+}
+
+/**
+ * @brief Generate puzzles for language, ruleset and area size from configuration
+ * @param[in] cfg Configuration
+ * @param[in] args Arguments of generate-puzzles command
+ * @return err Error if it occured
+ */
+func generatePuzzles(cfg *conf.Config, args flags.GeneratePuzzlesCommand) error {
+	gameCfg := cfg.Server.Game
+	output := string(args.Output)
+	if output == "" {
+		output = gameCfg.Puzzles
+	}
+	if output == "" {
+		return errors.New("Filename of puzzles is not given")
+	}
+
+	for _, d := range cfg.Server.Dictionaries {
+		if d.Name != gameCfg.Language {
+			continue
+		}
+		if _, err := dict.Load(d, cfg.Server.Rulesets); err != nil {
+			return logger.Trace(err, fmt.Sprintf("Can't load dictionary '%s'", d.Name))
+		}
+	}
+
+	if args.Measure == game.MeasureRarity {
+		if err := db.Init(cfg.Database); err != nil {
+			return logger.Trace(err, "Database error")
+		}
+	}
+
+	seed := args.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	gen, err := game.NewGenerator(gameCfg, seed, args.Measure)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	puzzles, err := gen.Generate(args.Count, args.Difficulty)
+	if err != nil {
+		return logger.Trace(err, fmt.Sprintf("Only %d puzzles are generated", len(puzzles)))
+	}
+
+	header := fmt.Sprintf("language=%s ruleset=%s size=%d measure=%s seed=%d",
+		gameCfg.Language, gameCfg.Ruleset, gameCfg.AreaSize, args.Measure, seed)
+	if err := game.SavePuzzles(output, header, puzzles); err != nil {
+		return err
+	}
+	logger.Log.Infof("%d puzzles generated into '%s' in %s", len(puzzles), output, time.Since(start))
+	return nil
+}
@@ -0,0 +1,261 @@
+/**
+ * @file admin.go
+ * @brief Admin commands
+ *
+ * Commands which are available only for users from Admins configuration
+ */
+package server
+
+import (
+	// System
+	"fmt"
+	"strconv"
+	"strings"
+
+	// Third-party
+
+	// Project
+	"github.com/BaldaGo/balda-go/conf"
+	"github.com/BaldaGo/balda-go/db"
+	"github.com/BaldaGo/balda-go/dict"
+	"github.com/BaldaGo/balda-go/game"
+	"github.com/BaldaGo/balda-go/logger"
+)
+
+/**
+ * @brief Predicate, check if user is admin
+ * @param[in] login User's login
+ */
+func (s *Server) isAdmin(login string) bool {
+	for _, a := range s.Admins {
+		if a == login {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * @brief Run admin command
+ * @param[in] arr Command with arguments
+ * @param[in] login User's login
+ * @return handled Flag if arr is admin command
+ * @return response Message for user
+ * @return err Error if it occured
+ *
+ * Commands:
+ * 	dict_add <word> [language]                        Add word to dictionary
+ * 	dict_remove <word> [language]                     Remove word from dictionary
+ * 	dict_ban <word> [language]                        Remove word from dictionary and forbid players to propose it
+ * 	dict_reload [language]                            Read dictionary files and runtime changes again
+ * 	proposals [limit]                                 Show words proposed by players
+ * 	approve <id>                                      Add proposed word to dictionary
+ * 	reject <id>                                       Reject proposed word
+ * 	start_word <word> [session]                       Choose start word of the next game in session (own session by default)
+ * 	start_policy <policy> [session]                   Choose policy of start word selection in session
+ * 	start_seed <seed> [session]                       Choose seed of random generator in session to reproduce game
+ * 	turn_order <policy> [session]                     Choose turn order policy in session (join, random, rating)
+ * 	challenge_rule <keep|undo> [session]              Decide running word challenge in session
+ * 	tournament_new <name> <format> <ruleset> [rounds] Create tournament (roundrobin, swiss)
+ * 	tournament_start <id>                             Close registration and pair the first round
+ * 	tournament_result <id> <player> <result>          Set result of player's game in the current round (win, draw, loss, forfeit)
+ * 	season_new <name> <first day> <last day>          Create season of leaderboards, days are YYYY-MM-DD
+ * 	season_archive <name>                             Archive final standings of season after its end
+ */
+func (s *Server) admin(arr []string, login string) (bool, string, error) {
+	switch arr[0] {
+	case "dict_add", "dict_remove", "dict_ban", "dict_reload", "proposals", "approve", "reject",
+		"start_word", "start_policy", "start_seed", "turn_order", "challenge_rule",
+		"tournament_new", "tournament_start", "tournament_result", "season_new", "season_archive":
+	default:
+		return false, "", nil
+	}
+
+	if !s.isAdmin(login) {
+		return true, "Only admins can run this command", nil
+	}
+
+	switch arr[0] {
+	case "dict_add", "dict_remove", "dict_ban":
+		if len(arr) < 2 {
+			return true, fmt.Sprintf("Usage: %s <word> [language]", arr[0]), nil
+		}
+		return s.changeWord(arr[1], s.language(arr[2:]), strings.TrimPrefix(arr[0], "dict_"), login)
+	case "dict_reload":
+		return s.reloadDictionary(s.language(arr[1:]))
+	case "proposals":
+		return s.proposals(arr[1:])
+	case "approve", "reject":
+		if len(arr) < 2 {
+			return true, fmt.Sprintf("Usage: %s <id>", arr[0]), nil
+		}
+		return s.resolveProposal(arr[1], arr[0] == "approve", login)
+	case "start_word", "start_policy", "start_seed", "turn_order", "challenge_rule":
+		if len(arr) < 2 {
+			return true, fmt.Sprintf("Usage: %s <value> [session]", arr[0]), nil
+		}
+		return s.sessionSetting(arr[0], arr[1], arr[2:], login)
+	case "tournament_new", "tournament_start", "tournament_result":
+		return s.tournamentAdmin(arr, login)
+	case "season_new", "season_archive":
+		return s.season(arr, login)
+	}
+
+	return true, "Unknown admin command", nil
+}
+
+/**
+ * @brief Language from optional argument
+ * @param[in] args Arguments, first one is language if given
+ */
+func (s *Server) language(args []string) string {
+	if len(args) > 0 {
+		return args[0]
+	}
+	return s.Language
+}
+
+/**
+ * @brief Change word of dictionary and save it into database
+ * @param[in] word Word typed by admin
+ * @param[in] language Name of dictionary
+ * @param[in] action Action with word (add, remove, ban)
+ * @param[in] login Admin's login
+ * @return Same values as admin
+ */
+func (s *Server) changeWord(word string, language string, action string, login string) (bool, string, error) {
+	word, err := dict.Change(language, word, action)
+	if err != nil {
+		return true, fmt.Sprintf("Can't %s word: %s", action, err.Error()), nil
+	}
+
+	if _, err := db.SetDictionaryWord(language, word, action, login); err != nil {
+		return true, "Word is changed, but isn't saved into database", err
+	}
+
+	logger.Log.Infof("Admin %s: %s '%s' in dictionary '%s'", login, action, word, language)
+	return true, fmt.Sprintf("Done: %s '%s' in dictionary '%s'", action, word, language), nil
+}
+
+/**
+ * @brief Load dictionary files and runtime changes from database again
+ * @param[in] language Name of dictionary
+ * @return Same values as admin
+ *
+ * Running games get new dictionary on their next move
+ */
+func (s *Server) reloadDictionary(language string) (bool, string, error) {
+	var cfg *conf.DictConf
+	for i := range s.Dictionaries {
+		if s.Dictionaries[i].Name == language {
+			cfg = &s.Dictionaries[i]
+		}
+	}
+	if cfg == nil {
+		return true, fmt.Sprintf("Unknown language '%s'", language), nil
+	}
+
+	actions, err := db.DictionaryWords(language)
+	if err != nil {
+		return true, databaseError, err
+	}
+
+	d, err := dict.Reload(*cfg, s.Rulesets, actions)
+	if err != nil {
+		return true, "Can't reload dictionary, old one is kept", err
+	}
+
+	logger.Log.Infof("Dictionary '%s' reloaded", language)
+	return true, fmt.Sprintf("Dictionary '%s' reloaded: %d words, %d runtime changes", language, d.Size(), len(actions)), nil
+}
+
+/**
+ * @brief Choose setting of the next game in session
+ * @param[in] cmd Command (start_word, start_policy, start_seed, turn_order or challenge_rule)
+ * @param[in] value Word, policy, seed or decision
+ * @param[in] args Optional id of session, admin's session by default
+ * @param[in] login Admin's login
+ * @return Same values as admin
+ */
+func (s *Server) sessionSetting(cmd string, value string, args []string, login string) (bool, string, error) {
+	id := s.Users[login]
+	if len(args) > 0 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 0 || n >= len(s.Sessions) {
+			return true, fmt.Sprintf("Session '%s' is not exists", args[0]), nil
+		}
+		id = n
+	}
+	g := s.Sessions[id].Game
+
+	if cmd == "start_seed" {
+		seed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return true, "Not correct command, not integer in seed", nil
+		}
+		if err := g.SetStartSeed(seed); err != nil {
+			return true, err.Error(), nil
+		}
+		logger.Log.Infof("Admin %s: seed %d in session %d", login, seed, id)
+		return true, fmt.Sprintf("Seed of session %d: %d", id, seed), nil
+	}
+
+	if cmd == "challenge_rule" {
+		if _, err := g.RuleChallenge(value, login); err != nil {
+			return true, err.Error(), nil
+		}
+		logger.Log.Infof("Admin %s: challenge decided '%s' in session %d", login, value, id)
+		return true, fmt.Sprintf("Challenge in session %d decided: %s", id, value), nil
+	}
+
+	if cmd == "turn_order" {
+		if err := g.SetTurnOrder(value); err != nil {
+			return true, err.Error(), nil
+		}
+		logger.Log.Infof("Admin %s: turn order '%s' in session %d", login, value, id)
+		return true, fmt.Sprintf("Turn order of session %d: %s", id, value), nil
+	}
+
+	if cmd == "start_policy" {
+		if err := g.SetStartPolicy(value); err != nil {
+			return true, err.Error(), nil
+		}
+		logger.Log.Infof("Admin %s: start policy '%s' in session %d", login, value, id)
+		return true, fmt.Sprintf("Start policy of session %d: %s", id, value), nil
+	}
+
+	word, err := g.SetStartWord(value)
+	if err != nil {
+		return true, fmt.Sprintf("Can't choose start word: %s", err.Error()), nil
+	}
+	logger.Log.Infof("Admin %s: start word '%s' in session %d", login, word, id)
+	return true, fmt.Sprintf("Start word of the next game in session %d: %s", id, word), nil
+}
+
+/**
+ * @brief Create or archive season of leaderboards
+ * @param[in] arr Command with arguments
+ * @param[in] login Admin's login
+ * @return Same values as admin
+ */
+func (s *Server) season(arr []string, login string) (bool, string, error) {
+	var response string
+	var err error
+	switch {
+	case arr[0] == "season_new" && len(arr) == 4:
+		response, err = game.CreateSeason(arr[1], arr[2], arr[3])
+	case arr[0] == "season_archive" && len(arr) == 2:
+		response, err = game.ArchiveSeason(arr[1])
+	case arr[0] == "season_new":
+		return true, "Usage: season_new <name> <YYYY-MM-DD> <YYYY-MM-DD>", nil
+	default:
+		return true, "Usage: season_archive <name>", nil
+	}
+
+	if err != nil {
+		logger.Log.Critical(err.Error())
+		return true, databaseError, err
+	}
+	logger.Log.Infof("Admin %s: %s", login, strings.Join(arr, " "))
+	return true, response, nil
+}
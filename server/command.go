@@ -0,0 +1,315 @@
+/**
+ * @file command.go
+ * @brief Server commands
+ *
+ * Commands which are handled by server before the game,
+ * because they don't depend on game state
+ */
+package server
+
+import (
+	// System
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	// Third-party
+
+	// Project
+	"github.com/BaldaGo/balda-go/db"
+	"github.com/BaldaGo/balda-go/dict"
+	"github.com/BaldaGo/balda-go/logger"
+)
+
+const (
+	databaseError  = "DATABASE_ERROR" ///< Message for user if database failed
+	proposalsLimit = 20               ///< Default number of shown proposals
+)
+
+/**
+ * @brief Run server command
+ * @param[in] str Command with arguments
+ * @param[in] user User, who sent command
+ * @param[in] errors Channel with failed connections
+ * @return handled Flag if str is server command
+ * @return response Message for user
+ * @return err Error if it occured
+ *
+ * Commands:
+ * 	propose <word> [language] Propose word, which is missing in dictionary
+ * 	team <message>            Send message only to teammates
+ * 	bots <n>                  Add bots to free places, game becomes practice
+ * 	rematch                   Vote for rematch after game is over
+ * 	leave                     Cancel rematch, all users of session return to lobby, or resign tournament game
+ * lobby commands (see lobbyCommand) and admin commands (see admin)
+ */
+func (s *Server) command(str string, user User, errors chan<- net.Conn) (bool, string, error) {
+	arr := strings.Fields(str)
+	if len(arr) == 0 {
+		return false, "", nil
+	}
+
+	switch arr[0] {
+	case "propose":
+		if len(arr) < 2 {
+			return true, "Usage: propose <word> [language]", nil
+		}
+		language := s.Sessions[user.sessionId].Game.Language
+		if len(arr) > 2 {
+			language = arr[2]
+		}
+		return s.propose(arr[1], language, user.login)
+	case "team":
+		return s.teamChat(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(str), "team")), user, errors)
+	case "bots":
+		if len(arr) < 2 {
+			return true, "Usage: bots <n>", nil
+		}
+		n, err := strconv.Atoi(arr[1])
+		if err != nil {
+			return true, "Not correct command, not integer in number of bots", nil
+		}
+		return s.addBots(n, user, errors)
+	case "rematch":
+		return s.rematch(user, errors)
+	case "leave":
+		if g := s.Sessions[user.sessionId].Game; g.Reserved() && !g.Finished() {
+			s.leaveTournament(user.sessionId, user.login, errors)
+			return true, "", nil
+		}
+		if !s.Sessions[user.sessionId].Game.Finished() {
+			return true, "You can leave only after game is over", nil
+		}
+		s.toLobby(user.sessionId, fmt.Sprintf("%s doesn't want rematch.", user.login), errors)
+		return true, "", nil
+	}
+
+	if handled, response, err := s.lobbyCommand(arr, user.login); handled {
+		return handled, response, err
+	}
+	return s.admin(arr, user.login)
+}
+
+/**
+ * @brief Run command, which doesn't depend on session
+ * @param[in] arr Command with arguments
+ * @param[in] login User's login
+ * @return Same values as command
+ *
+ * Commands of correspondence games (see corr), daily puzzle (see daily),
+ * training (see train) and tournaments (see tournament), they are available in lobby too
+ */
+func (s *Server) lobbyCommand(arr []string, login string) (bool, string, error) {
+	if handled, response, err := s.corr(arr, login); handled {
+		return handled, response, err
+	}
+	if handled, response, err := s.daily(arr, login); handled {
+		return handled, response, err
+	}
+	if handled, response, err := s.tournament(arr, login); handled {
+		return handled, response, err
+	}
+	return s.train(arr, login)
+}
+
+/**
+ * @brief Put word into the queue of proposals
+ * @param[in] word Word typed by player
+ * @param[in] language Name of dictionary
+ * @param[in] login Player's login
+ * @return Same values as command
+ */
+func (s *Server) propose(word string, language string, login string) (bool, string, error) {
+	d, ok := dict.Get(language)
+	if !ok {
+		return true, fmt.Sprintf("Unknown language '%s'", language), nil
+	}
+
+	word, err := d.Normalize(word)
+	if err != nil {
+		return true, fmt.Sprintf("Invalid word: %s", err.Error()), nil
+	}
+	if d.IsBanned(word) {
+		return true, fmt.Sprintf("Word '%s' is banned", word), nil
+	}
+	if d.CheckWord(word) {
+		return true, fmt.Sprintf("Word '%s' is already in dictionary", word), nil
+	}
+
+	proposal, pending, err := db.AddProposal(language, word, login)
+	if err != nil {
+		return true, databaseError, err
+	}
+	if pending {
+		return true, fmt.Sprintf("Word '%s' is already proposed and waits for admin", word), nil
+	}
+
+	logger.Log.Infof("User %s proposed '%s' for dictionary '%s'", login, word, language)
+	return true, fmt.Sprintf("Thank you! Proposal #%d '%s' waits for admin", proposal.ID, word), nil
+}
+
+/**
+ * @brief Show proposals waiting for admin
+ * @param[in] args Optional limit
+ * @return Same values as command
+ */
+func (s *Server) proposals(args []string) (bool, string, error) {
+	limit := proposalsLimit
+	if len(args) > 0 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n <= 0 {
+			return true, "Not correct command, not positive integer in limit", nil
+		}
+		limit = n
+	}
+
+	res, err := db.PendingProposals(uint(limit))
+	if err != nil {
+		return true, databaseError, err
+	}
+	if len(res) == 0 {
+		return true, "No proposals", nil
+	}
+
+	var prepare []string
+	for i := range res {
+		prepare = append(prepare,
+			fmt.Sprintf("#%d %s (%s) by %s",
+				res[i].ID,
+				res[i].Word,
+				res[i].Language,
+				res[i].User.Name))
+	}
+	return true, strings.Join(prepare, "\n\r"), nil
+}
+
+/**
+ * @brief Approve or reject proposal
+ * @param[in] id Id of proposal
+ * @param[in] approve Flag, add word to dictionary if it is true
+ * @param[in] login Admin's login
+ * @return Same values as command
+ *
+ * Word is added to dictionary before proposal is resolved,
+ * so proposal stays pending if the word can't be added
+ */
+func (s *Server) resolveProposal(id string, approve bool, login string) (bool, string, error) {
+	n, err := strconv.Atoi(strings.TrimPrefix(id, "#"))
+	if err != nil || n <= 0 {
+		return true, "Not correct command, not positive integer in id", nil
+	}
+
+	status := db.ProposalRejected
+	if approve {
+		status = db.ProposalApproved
+	}
+
+	proposal, err := db.PendingProposal(uint(n))
+	if err != nil {
+		return true, fmt.Sprintf("Proposal #%d is not found or already resolved", n), nil
+	}
+
+	if approve {
+		if _, err := dict.Change(proposal.Language, proposal.Word, dict.ActionAdd); err != nil {
+			return true, fmt.Sprintf("Can't add word: %s", err.Error()), nil
+		}
+	}
+
+	if proposal, err = db.ResolveProposal(uint(n), status, login); err != nil {
+		if db.NotFound(err) {
+			return true, fmt.Sprintf("Proposal #%d is not found or already resolved", n), nil
+		}
+		if approve {
+			return true, "Word is added, but isn't saved into database", err
+		}
+		return true, databaseError, err
+	}
+
+	if !approve {
+		logger.Log.Infof("Admin %s rejected '%s' proposed by %s", login, proposal.Word, proposal.User.Name)
+		return true, fmt.Sprintf("Proposal #%d '%s' rejected", n, proposal.Word), nil
+	}
+
+	logger.Log.Infof("Admin %s: %s '%s' in dictionary '%s'", login, dict.ActionAdd, proposal.Word, proposal.Language)
+	return true, fmt.Sprintf("Proposal #%d approved, '%s' added to dictionary '%s'", n, proposal.Word, proposal.Language), nil
+}
+
+/**
+ * @brief Vote for rematch and start it if all users agree
+ * @param[in] user User, who voted
+ * @param[in] errors Channel with failed connections
+ * @return Same values as command
+ */
+func (s *Server) rematch(user User, errors chan<- net.Conn) (bool, string, error) {
+	g := s.Sessions[user.sessionId].Game
+	votes, all, err := g.VoteRematch(user.login)
+	if err != nil {
+		return true, err.Error(), nil
+	}
+
+	if !all {
+		s.broadcast(fmt.Sprintf("I want rematch! (%d/%d)", votes, len(s.Sessions[user.sessionId].Users)), user.login, BC_OTHER, errors)
+		return true, "Your vote is accepted, waiting for other players", nil
+	}
+
+	if err := g.Restart(); err != nil {
+		return true, databaseError, err
+	}
+	if err := s.startIfFull(user.sessionId, errors); err != nil {
+		s.toLobby(user.sessionId, err.Error(), errors)
+		return true, "", err
+	}
+	return true, "", nil
+}
+
+/**
+ * @brief Add bots to session of user and start game if session is full
+ * @param[in] n Number of bots
+ * @param[in] user User, who added bots
+ * @param[in] errors Channel with failed connections
+ * @return Same values as command
+ */
+func (s *Server) addBots(n int, user User, errors chan<- net.Conn) (bool, string, error) {
+	membership.Lock()
+	msg, err := s.Sessions[user.sessionId].Game.AddBots(n)
+	membership.Unlock()
+	if err != nil {
+		return true, err.Error(), nil
+	}
+
+	s.broadcast(msg, user.login, BC_ALL, errors)
+	if err := s.startIfFull(user.sessionId, errors); err != nil {
+		logger.Log.Warning(err.Error())
+		s.toLobby(user.sessionId, err.Error(), errors)
+	}
+	return true, "", nil
+}
+
+/**
+ * @brief Send message to teammates of user
+ * @param[in] msg Message
+ * @param[in] user User, who sent message
+ * @param[in] errors Channel with failed connections
+ * @return Same values as command
+ */
+func (s *Server) teamChat(msg string, user User, errors chan<- net.Conn) (bool, string, error) {
+	g := s.Sessions[user.sessionId].Game
+	team := g.TeamOf(user.login)
+	if team == "" {
+		return true, "You aren't in a team", nil
+	}
+	if msg == "" {
+		return true, "Usage: team <message>", nil
+	}
+
+	raw := fmt.Sprintf("%s (team %s)> %s\n\r", user.login, team, msg)
+	for _, login := range g.TeamMembers(team) {
+		for _, u := range s.Sessions[user.sessionId].Users {
+			if u.login == login && login != user.login {
+				go asyncWriteBytes(u.conn, []byte(raw), errors)
+			}
+		}
+	}
+	return true, "Sent to your team", nil
+}
@@ -0,0 +1,105 @@
+/**
+ * @file corr.go
+ * @brief Correspondence commands
+ *
+ * Commands of correspondence games, they are available in lobby and in session
+ */
+package server
+
+import (
+	// System
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	// Third-party
+
+	// Project
+	"github.com/BaldaGo/balda-go/logger"
+)
+
+/**
+ * @brief Run command of correspondence games
+ * @param[in] arr Command with arguments
+ * @param[in] login User's login
+ * @return handled Flag if arr is command of correspondence games
+ * @return response Message for user
+ * @return err Error if it occured
+ *
+ * Commands:
+ * 	corr_new [hours] <user> [user...]     Start game with users, hours per move (default from configuration)
+ * 	corr_list                             Show your running games
+ * 	corr_show <id>                        Show area, scores and words of game
+ * 	corr_put <id> <x> <y> <letter> <word> Put letter and make word (if your step is now)
+ * 	corr_skip <id>                        Skip move (if your step is now)
+ */
+func (s *Server) corr(arr []string, login string) (bool, string, error) {
+	if len(arr) == 0 || !strings.HasPrefix(arr[0], "corr_") {
+		return false, "", nil
+	}
+
+	response, err := s.corrCommand(arr, login)
+	if err != nil {
+		logger.Log.Critical(err.Error())
+		return true, databaseError, err
+	}
+	return true, response, nil
+}
+
+/**
+ * @brief Parse arguments and run command of correspondence games
+ * @param[in] arr Command with arguments
+ * @param[in] login User's login
+ * @return response Message for user
+ * @return err Error if database failed
+ */
+func (s *Server) corrCommand(arr []string, login string) (string, error) {
+	if arr[0] == "corr_new" {
+		args := arr[1:]
+		var moveTime time.Duration
+		if len(args) > 0 {
+			if hours, err := strconv.Atoi(args[0]); err == nil {
+				if hours <= 0 {
+					return "Not correct command, not positive integer in hours", nil
+				}
+				moveTime = time.Duration(hours) * time.Hour
+				args = args[1:]
+			}
+		}
+		if len(args) == 0 {
+			return "Usage: corr_new [hours] <user> [user...]", nil
+		}
+		return s.Corr.New(login, args, moveTime)
+	}
+	if arr[0] == "corr_list" {
+		return s.Corr.List(login)
+	}
+
+	if len(arr) < 2 {
+		return fmt.Sprintf("Usage: %s <id>", arr[0]), nil
+	}
+	id, err := strconv.Atoi(strings.TrimPrefix(arr[1], "#"))
+	if err != nil || id <= 0 {
+		return "Not correct command, not positive integer in id", nil
+	}
+
+	switch arr[0] {
+	case "corr_show":
+		return s.Corr.Show(uint(id), login)
+	case "corr_skip":
+		return s.Corr.Skip(uint(id), login)
+	case "corr_put":
+		if len(arr) < 6 {
+			return "Usage: corr_put <id> <x> <y> <letter> <word>", nil
+		}
+		x, errX := strconv.Atoi(arr[2])
+		y, errY := strconv.Atoi(arr[3])
+		if errX != nil || errY != nil {
+			return "Not correct command, not integer in coordinates", nil
+		}
+		return s.Corr.Put(uint(id), login, x, y, arr[4], arr[5])
+	}
+
+	return "Unknown command of correspondence games", nil
+}
@@ -0,0 +1,57 @@
+/**
+ * @file daily.go
+ * @brief Daily puzzle command
+ *
+ * Command of daily puzzle, it is available in lobby and in session
+ */
+package server
+
+import (
+	// System
+	"strconv"
+
+	// Third-party
+
+	// Project
+	"github.com/BaldaGo/balda-go/logger"
+)
+
+/**
+ * @brief Run command of daily puzzle
+ * @param[in] arr Command with arguments
+ * @param[in] login User's login
+ * @return handled Flag if arr is command of daily puzzle
+ * @return response Message for user
+ * @return err Error if it occured
+ *
+ * Commands:
+ * 	daily                         Show puzzle of the day, your result and leaderboard
+ * 	daily <x> <y> <letter> <word> Try move in puzzle of the day
+ */
+func (s *Server) daily(arr []string, login string) (bool, string, error) {
+	if len(arr) == 0 || arr[0] != "daily" {
+		return false, "", nil
+	}
+
+	var response string
+	var err error
+	switch {
+	case len(arr) == 1:
+		response, err = s.Daily.Show(login)
+	case len(arr) == 5:
+		x, errX := strconv.Atoi(arr[1])
+		y, errY := strconv.Atoi(arr[2])
+		if errX != nil || errY != nil {
+			return true, "Not correct command, not integer in coordinates", nil
+		}
+		response, err = s.Daily.Submit(login, x, y, arr[3], arr[4])
+	default:
+		return true, "Usage: daily [<x> <y> <letter> <word>]", nil
+	}
+
+	if err != nil {
+		logger.Log.Critical(err.Error())
+		return true, databaseError, err
+	}
+	return true, response, nil
+}
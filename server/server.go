@@ -25,6 +25,7 @@ import (
 	// Project
 
 	"github.com/BaldaGo/balda-go/conf"
+	"github.com/BaldaGo/balda-go/db"
 	"github.com/BaldaGo/balda-go/dict"
 	"github.com/BaldaGo/balda-go/game"
 	"github.com/BaldaGo/balda-go/logger"
@@ -62,6 +63,14 @@ type Server struct {
 	Signals           chan os.Signal ///< Channel of system signals like SIGINT and SIGKILL
 	WaitTime          time.Duration
 	SystemLogin       string
+	Admins            []string           ///< Logins of users which can run admin commands
+	Dictionaries      []conf.DictConf    ///< Configurations of dictionaries to reload them
+	Rulesets          []conf.RulesetConf ///< Rulesets which filter words of every dictionary
+	Language          string             ///< Default language of games
+	Corr              *game.Corr         ///< Correspondence games, which are stored in database
+	Daily             *game.Daily        ///< Daily puzzles
+	Training          *game.Training     ///< Training puzzles of users
+	Tournaments       *game.Tournaments  ///< Tournaments, which are stored in database
 }
 
 /**
@@ -79,6 +88,10 @@ func New(cfg conf.ServerConf) Server {
 	s.TimeoutForLogin = cfg.TimeoutForLogin * time.Second
 	s.SystemLogin = cfg.SystemLogin
 	s.WaitTime = cfg.WaitTime * time.Millisecond
+	s.Admins = cfg.Admins
+	s.Dictionaries = cfg.Dictionaries
+	s.Rulesets = cfg.Rulesets
+	s.Language = cfg.Game.Language
 
 	s.Timeout = cfg.Game.Timeout * time.Second
 	s.MaxUsernameLength = cfg.Game.MaxUsernameLength
@@ -95,8 +108,35 @@ func New(cfg conf.ServerConf) Server {
  */
 
 func (s *Server) PreRun(cfg conf.ServerConf) error {
-	dict.Init(cfg.Game.AreaSize, cfg.DictPath)
+	for _, d := range cfg.Dictionaries {
+		if _, err := dict.Load(d, cfg.Rulesets); err != nil {
+			return logger.Trace(err, fmt.Sprintf("Can't load dictionary '%s'", d.Name))
+		}
+
+		actions, err := db.DictionaryWords(d.Name)
+		if err != nil {
+			return logger.Trace(err, "Database error")
+		}
+		if err := dict.Apply(d.Name, actions); err != nil {
+			return err
+		}
+	}
 
+	var puzzles []game.Puzzle
+	if cfg.Game.Puzzles != "" {
+		var err error
+		if puzzles, err = game.LoadPuzzles(cfg.Game.Puzzles, cfg.Game); err != nil {
+			logger.Log.Warning(logger.Trace(err, "Puzzles aren't loaded, they will be generated").Error())
+		}
+	}
+
+	s.Corr = game.NewCorr(cfg.Game)
+	s.Daily = game.NewDaily(cfg.Game)
+	s.Training = game.NewTraining(cfg.Game, puzzles, s.Daily)
+	s.Tournaments = game.NewTournaments(cfg.Game)
+	if err := s.Tournaments.Restore(); err != nil {
+		return logger.Trace(err, "Database error")
+	}
 	s.Pool = NewPool(cfg.Concurrency)
 	s.Sessions = make([]Session, cfg.NumberOfGames)
 
@@ -110,7 +150,10 @@ func (s *Server) PreRun(cfg conf.ServerConf) error {
 		if err != nil {
 			return err
 		}
+		s.Sessions[i].Game.Notifier = s.gameNotifier(i)
+		s.Sessions[i].Game.OnFinish = s.Tournaments.Report
 	}
+	s.scheduleTournaments()
 
 	s.Pool.Run()
 	logger.Log.Debugf("Server is configurated with next options: %+v\n", cfg)
@@ -216,7 +259,7 @@ func work(ctx context.Context) error {
 	user := <-users
 	logger.Log.Infof("User from %s logined as %s and associated with session %d", c.RemoteAddr(), user.login, user.sessionId)
 
-	s.broadcast(fmt.Sprintf("Welcome %s!\n\rPlease, wait other players...", user.login), user.login, BC_ALL, errors)
+	s.welcome(user, errors)
 	go asyncReadBytes(c, buffer, errors)
 
 	terminated := false
@@ -225,6 +268,34 @@ func work(ctx context.Context) error {
 		case result := <-buffer:
 			logger.Log.Debugf("Readed '%s' from client", result)
 
+			// Lobby
+			id, inSession := s.sessionOf(user.login)
+			if !inSession {
+				if handled, response, _ := s.lobbyCommand(strings.Fields(string(result)), user.login); handled {
+					s.reply(c, fmt.Sprintf("%s\n\r%s", response, joinPrompt), errors)
+				} else if u, err := s.joinSession(c, user.login, string(result)); err != nil {
+					s.reply(c, fmt.Sprintf("%s\n\r%s", err.Error(), joinPrompt), errors)
+				} else {
+					user = u
+					s.welcome(user, errors)
+				}
+				go asyncReadBytes(c, buffer, errors)
+				break
+			}
+			user.sessionId = id
+
+			// Server commands
+			if handled, response, err := s.command(string(result), user, errors); handled {
+				if err != nil {
+					logger.Log.Warning(logger.Trace(err, "Server command failed").Error())
+				}
+				if response != "" {
+					s.reply(c, response, errors)
+				}
+				go asyncReadBytes(c, buffer, errors)
+				break
+			}
+
 			// Game interactive
 			play, response, err := s.Sessions[user.sessionId].Game.Continue(string(result), user.login)
 			logger.Log.Debugf("Generic answers '%s'. Continue: %b", response, play)
@@ -239,17 +310,17 @@ func work(ctx context.Context) error {
 			if !play {
 				s.broadcast(response, user.login, BC_ALL, errors)
 				logger.Log.Infof("Game over! %s", response)
-				for _, u := range s.Sessions[user.sessionId].Users {
-					u.conn.Close()
-				}
-				terminated = true
-			} else {
+				s.afterGame(user.sessionId, errors)
+			} else if response != "" {
 				s.broadcast(response, user.login, BC_ALL, errors)
 			}
 
 			go asyncReadBytes(c, buffer, errors)
 
 		case <-time.After(s.Timeout):
+			if id, ok := s.sessionOf(user.login); !ok || !s.Sessions[id].Game.Started() || s.Sessions[id].Game.Mode == game.ModeHunt {
+				break
+			}
 			logger.Log.Warning("Timeout while reading...")
 			s.broadcast(fmt.Sprintf("%s doesn't catch his move", user.login), user.login, BC_OTHER, errors)
 			s.broadcast("You're too slow!", s.SystemLogin, BC_SELF, errors)
@@ -261,6 +332,11 @@ func work(ctx context.Context) error {
 
 		case c := <-errors:
 			logger.Log.Warningf("User from %s failed", c.RemoteAddr())
+			if id, ok := s.sessionOf(user.login); ok && s.Sessions[id].Game.Finished() {
+				s.toLobby(id, fmt.Sprintf("%s left, rematch is cancelled.", user.login), errors)
+			} else if ok && s.Sessions[id].Game.Reserved() {
+				s.leaveTournament(id, user.login, errors)
+			}
 			return c.Close()
 
 		case <-ctx.Done():
@@ -315,6 +391,82 @@ func asyncReadBytes(c net.Conn, buffer chan<- []byte, errors chan<- net.Conn) {
 	}
 }
 
+/**
+ * @brief Send system message only to one connection
+ * @param[in] c Connection
+ * @param[in] raw Message
+ * @param[in] errors Channel with failed connections
+ */
+func (s *Server) reply(c net.Conn, raw string, errors chan<- net.Conn) {
+	go asyncWriteBytes(c, []byte(fmt.Sprintf("%s> %s\n\r", s.SystemLogin, raw)), errors)
+}
+
+/**
+ * @brief Send system message to all users of session
+ * @param[in] id Id of session
+ * @param[in] raw Message
+ * @param[in] errors Channel with failed connections
+ */
+func (s *Server) notify(id int, raw string, errors chan<- net.Conn) {
+	s.notifyUsers(s.Sessions[id].Users, raw, errors)
+}
+
+/**
+ * @brief Send system message to users
+ * @param[in] users Users
+ * @param[in] raw Message
+ * @param[in] errors Channel with failed connections
+ */
+func (s *Server) notifyUsers(users []User, raw string, errors chan<- net.Conn) {
+	msg := fmt.Sprintf("%s> %s\n\r", s.SystemLogin, raw)
+	for _, i := range users {
+		go asyncWriteBytes(i.conn, []byte(msg), errors)
+	}
+}
+
+/**
+ * @brief Copy of users of session
+ * @param[in] id Id of session
+ * @return users Users of session
+ *
+ * Takes membership lock, so users can't join or leave while they are copied
+ */
+func (s *Server) sessionUsers(id int) []User {
+	membership.Lock()
+	defer membership.Unlock()
+	return append([]User{}, s.Sessions[id].Users...)
+}
+
+/**
+ * @brief Create callback, which sends messages of game to users of session
+ * @param[in] id Id of session
+ * @return notifier Callback for game
+ *
+ * Callback is called by timers too, so users of session are copied under membership lock
+ */
+func (s *Server) gameNotifier(id int) game.Notifier {
+	return func(users []string, msg string, over bool) {
+		members := s.sessionUsers(id)
+		errors := make(chan net.Conn, 2*len(members))
+		if len(users) == 0 {
+			s.notifyUsers(members, msg, errors)
+		} else {
+			for _, u := range members {
+				for _, login := range users {
+					if u.login == login {
+						s.reply(u.conn, msg, errors)
+					}
+				}
+			}
+		}
+
+		if over {
+			logger.Log.Infof("Game over in session %d", id)
+			s.afterGame(id, errors)
+		}
+	}
+}
+
 func (s *Server) broadcast(raw string, login string, flags int, errors chan<- net.Conn) {
 	msg := fmt.Sprintf("%s> %s\n\r", login, raw)
 	sessionID := s.Users[login]
@@ -0,0 +1,239 @@
+/**
+ * @file tournament.go
+ * @brief Tournament commands
+ *
+ * Commands of tournaments, they are available in lobby and in session.
+ * Games of tournament rounds are placed into free sessions, players who don't join
+ * the session in time or leave it lose by forfeit
+ */
+package server
+
+import (
+	// System
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	// Third-party
+
+	// Project
+	"github.com/BaldaGo/balda-go/logger"
+)
+
+/**
+ * @brief Run command of tournaments
+ * @param[in] arr Command with arguments
+ * @param[in] login User's login
+ * @return handled Flag if arr is command of tournaments
+ * @return response Message for user
+ * @return err Error if it occured
+ *
+ * Commands:
+ * 	tournament_list      Show tournaments, which aren't finished
+ * 	tournament_show <id> Show standings and games of the current round
+ * 	tournament_join <id> Join tournament before it starts
+ */
+func (s *Server) tournament(arr []string, login string) (bool, string, error) {
+	if len(arr) == 0 {
+		return false, "", nil
+	}
+
+	var response string
+	var err error
+	switch arr[0] {
+	case "tournament_list":
+		response, err = s.Tournaments.List()
+	case "tournament_show", "tournament_join":
+		id, ok := tournamentID(arr)
+		if !ok {
+			return true, fmt.Sprintf("Usage: %s <id>", arr[0]), nil
+		}
+		if arr[0] == "tournament_show" {
+			response, err = s.Tournaments.Show(id)
+		} else {
+			response, err = s.Tournaments.Join(id, login)
+		}
+	default:
+		return false, "", nil
+	}
+
+	if err != nil {
+		logger.Log.Critical(err.Error())
+		return true, databaseError, err
+	}
+	return true, response, nil
+}
+
+/**
+ * @brief Run admin command of tournaments
+ * @param[in] arr Command with arguments
+ * @param[in] login Admin's login
+ * @return Same values as admin
+ */
+func (s *Server) tournamentAdmin(arr []string, login string) (bool, string, error) {
+	var response string
+	var err error
+	switch arr[0] {
+	case "tournament_new":
+		if len(arr) < 4 {
+			return true, "Usage: tournament_new <name> <roundrobin|swiss> <ruleset> [rounds]", nil
+		}
+		rounds := 0
+		if len(arr) > 4 {
+			if rounds, err = strconv.Atoi(arr[4]); err != nil {
+				return true, "Not correct command, not integer in rounds", nil
+			}
+		}
+		response, err = s.Tournaments.Create(arr[1], arr[2], arr[3], rounds)
+	case "tournament_result":
+		id, ok := tournamentID(arr)
+		if !ok || len(arr) < 4 {
+			return true, "Usage: tournament_result <id> <player> <win|draw|loss|forfeit>", nil
+		}
+		var gameID uint
+		if gameID, response, err = s.Tournaments.Result(id, arr[2], arr[3]); err == nil && gameID != 0 {
+			s.freeTournamentSession(gameID, fmt.Sprintf("Result of tournament game is set by admin. %s", response))
+		}
+	default:
+		id, ok := tournamentID(arr)
+		if !ok {
+			return true, "Usage: tournament_start <id>", nil
+		}
+		response, err = s.Tournaments.Start(id)
+	}
+
+	if err != nil {
+		logger.Log.Critical(err.Error())
+		return true, databaseError, err
+	}
+	logger.Log.Infof("Admin %s: %s", login, strings.Join(arr, " "))
+	s.scheduleTournaments()
+	return true, response, nil
+}
+
+/**
+ * @brief Id of tournament from the first argument of command
+ * @param[in] arr Command with arguments
+ * @return id Id of tournament
+ * @return ok False if id isn't positive integer
+ */
+func tournamentID(arr []string) (uint, bool) {
+	if len(arr) < 2 {
+		return 0, false
+	}
+	id, err := strconv.Atoi(strings.TrimPrefix(arr[1], "#"))
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	return uint(id), true
+}
+
+/**
+ * @brief Place waiting tournament games into free sessions
+ *
+ * Players see their sessions in tournament_show and when they log in
+ */
+func (s *Server) scheduleTournaments() {
+	games, err := s.Tournaments.Pending()
+	if err != nil {
+		logger.Log.Critical(logger.Trace(err, "Can't read tournament games").Error())
+		return
+	}
+
+	membership.Lock()
+	defer membership.Unlock()
+
+	id := 0
+	for _, tg := range games {
+		for ; id < len(s.Sessions) && !s.Sessions[id].Game.Free(); id++ {
+		}
+		if id == len(s.Sessions) {
+			return
+		}
+
+		g := s.Sessions[id].Game
+		if err := g.Reserve([]string{tg.Player1.Name, tg.Player2.Name}, tg.Tournament.Language, tg.Tournament.Ruleset); err != nil {
+			logger.Log.Warning(logger.Trace(err, fmt.Sprintf("Tournament game %d isn't placed", tg.ID)).Error())
+			continue
+		}
+		if err := s.Tournaments.Assign(tg, id, g.GameID()); err != nil {
+			logger.Log.Critical(logger.Trace(err, "Can't save tournament game").Error())
+			if err := g.Reset(); err != nil {
+				logger.Log.Critical(err.Error())
+			}
+			continue
+		}
+		session, gameID := id, g.GameID()
+		time.AfterFunc(s.Tournaments.NoShowTime, func() { s.noShow(session, gameID) })
+		logger.Log.Infof("Tournament #%d: %s - %s in session %d", tg.TournamentID, tg.Player1.Name, tg.Player2.Name, id)
+	}
+}
+
+/**
+ * @brief Count forfeit of players, who didn't join tournament game in time
+ * @param[in] id Id of session
+ * @param[in] gameID Id of the game in database, which was placed into session
+ *
+ * Called by timer, nothing happens if game started or session is given to another game
+ */
+func (s *Server) noShow(id int, gameID uint) {
+	defer s.scheduleTournaments()
+	membership.Lock()
+	defer membership.Unlock()
+
+	g := s.Sessions[id].Game
+	if !g.Reserved() || g.GameID() != gameID || g.Started() || g.Finished() {
+		return
+	}
+
+	var absent []string
+	for _, p := range g.ReservedPlayers() {
+		if sid, ok := s.Users[p]; !ok || sid != id {
+			absent = append(absent, p)
+		}
+	}
+	if len(absent) == 0 {
+		return
+	}
+
+	msg, err := s.Tournaments.Forfeit(gameID, absent)
+	if err != nil {
+		logger.Log.Critical(logger.Trace(err, "Tournament result isn't saved").Error())
+		return
+	}
+	errors := make(chan net.Conn, 2*len(s.Sessions[id].Users))
+	s.clearSession(id, fmt.Sprintf("%s didn't join tournament game in time. %s", strings.Join(absent, ", "), msg), errors)
+}
+
+/**
+ * @brief Player leaves tournament game before it is over and loses by forfeit
+ * @param[in] id Id of session
+ * @param[in] login Player's login
+ * @param[in] errors Channel with failed connections
+ */
+func (s *Server) leaveTournament(id int, login string, errors chan<- net.Conn) {
+	msg, err := s.Tournaments.Forfeit(s.Sessions[id].Game.GameID(), []string{login})
+	if err != nil {
+		logger.Log.Critical(logger.Trace(err, "Tournament result isn't saved").Error())
+	}
+	s.toLobby(id, fmt.Sprintf("%s left tournament game. %s", login, msg), errors)
+}
+
+/**
+ * @brief Return players of tournament game to lobby, when its result is set by admin
+ * @param[in] gameID Id of the game in database
+ * @param[in] reason Message for players
+ */
+func (s *Server) freeTournamentSession(gameID uint, reason string) {
+	membership.Lock()
+	defer membership.Unlock()
+
+	for id := range s.Sessions {
+		if g := s.Sessions[id].Game; g.Reserved() && g.GameID() == gameID {
+			errors := make(chan net.Conn, 2*len(s.Sessions[id].Users))
+			s.clearSession(id, reason, errors)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+/**
+ * @file training.go
+ * @brief Training command
+ *
+ * Command of training puzzles, it is available in lobby and in session
+ */
+package server
+
+import (
+	// System
+	"strconv"
+
+	// Third-party
+
+	// Project
+	"github.com/BaldaGo/balda-go/logger"
+)
+
+/**
+ * @brief Run command of training puzzles
+ * @param[in] arr Command with arguments
+ * @param[in] login User's login
+ * @return handled Flag if arr is command of training puzzles
+ * @return response Message for user
+ * @return err Error if it occured
+ *
+ * Commands:
+ * 	train [easy|medium|hard]      Take new puzzle
+ * 	train show                    Show current puzzle
+ * 	train answer                  Give up and show answer of current puzzle
+ * 	train <x> <y> <letter> <word> Try move in current puzzle
+ */
+func (s *Server) train(arr []string, login string) (bool, string, error) {
+	if len(arr) == 0 || arr[0] != "train" {
+		return false, "", nil
+	}
+
+	var response string
+	var err error
+	switch {
+	case len(arr) == 1:
+		response, err = s.Training.Next(login, "")
+	case len(arr) == 2 && arr[1] == "show":
+		response, err = s.Training.Show(login)
+	case len(arr) == 2 && arr[1] == "answer":
+		response = s.Training.Answer(login)
+	case len(arr) == 2:
+		response, err = s.Training.Next(login, arr[1])
+	case len(arr) == 5:
+		x, errX := strconv.Atoi(arr[1])
+		y, errY := strconv.Atoi(arr[2])
+		if errX != nil || errY != nil {
+			return true, "Not correct command, not integer in coordinates", nil
+		}
+		response, err = s.Training.Try(login, x, y, arr[3], arr[4])
+	default:
+		return true, "Usage: train [easy|medium|hard|show|answer|<x> <y> <letter> <word>]", nil
+	}
+
+	if err != nil {
+		logger.Log.Critical(err.Error())
+		return true, "Training is not available now", err
+	}
+	return true, response, nil
+}
@@ -16,6 +16,7 @@ import (
 	"net"
 	"strconv"
 	"strings"
+	"sync"
 	"unicode/utf8"
 
 	// Third-party
@@ -30,6 +31,10 @@ var (
 	clear_home = []byte{27, 91, 72, 27, 91, 50, 74} ///< Bytes sequence to clear user screen
 )
 
+const joinPrompt = "Please, enter the number of game your want to assign: " ///< Prompt of session id
+
+var membership sync.Mutex // Lock of Server.Users and users of sessions
+
 /**
  * @class User
  * @brief Class, provides information about User
@@ -67,23 +72,81 @@ func login(ctx context.Context) error {
 	c = ctx.Value(ConnKey).(net.Conn)
 	user = ctx.Value(ChanKey).(chan User)
 
+	io := bufio.NewReader(c)
+	name, err := authenticate(s, c, io)
+	if err != nil {
+		return err
+	}
+
+	// Show correspondence games, where it is turn of user
+	if inbox, err := s.Corr.Inbox(name); err != nil {
+		logger.Log.Warning(logger.Trace(err, "Can't read correspondence games").Error())
+	} else if inbox != "" {
+		c.Write([]byte(inbox + "\n\r"))
+	}
+
+	// Show tournament games, which wait for user
+	if inbox, err := s.Tournaments.Inbox(name); err != nil {
+		logger.Log.Warning(logger.Trace(err, "Can't read tournament games").Error())
+	} else if inbox != "" {
+		c.Write([]byte(inbox + "\n\r"))
+	}
+
+	// Read and validate session id, lobby commands are answered before it
+	c.Write([]byte(joinPrompt))
+	line, err := io.ReadString('\n')
+	for ; err == nil; line, err = io.ReadString('\n') {
+		handled, response, _ := s.lobbyCommand(strings.Fields(line), name)
+		if !handled {
+			break
+		}
+		c.Write([]byte(fmt.Sprintf("%s\n\r%s", response, joinPrompt)))
+	}
+	if err != nil {
+		return logger.Trace(err, "Communication error")
+	}
+
+	newUser, err := s.joinSession(c, name, line)
+	if err != nil {
+		return err
+	}
+
+	user <- newUser
+	logger.Log.Debugf("User from %s associated with session: %d", c.RemoteAddr(), newUser.sessionId)
+
+	return nil
+}
+
+/**
+ * @brief Ask user's name and password and check them
+ * @param[in] s Server
+ * @param[in] c Connection
+ * @param[in] io Reader of connection
+ * @return name User's login or error if it occured
+ *
+ * Creates user if not exists, names of bots are reserved
+ */
+func authenticate(s *Server, c net.Conn, io *bufio.Reader) (string, error) {
 	// Say welcome and ask username
 	c.Write([]byte("Welcome to balda game!\nPlease, enter your name to log in: "))
 
 	// Read and validate username
-	io := bufio.NewReader(c)
 	line, err := io.ReadString('\n')
 	if err != nil {
-		return logger.Trace(err, "Communication error")
+		return "", logger.Trace(err, "Communication error")
 	}
 
 	name := strings.Replace(strings.Replace(line, "\n", "", -1), "\r", "", -1)
 	if name == "" {
-		return errors.New("Empty name")
+		return "", errors.New("Empty name")
 	}
 
 	if utf8.RuneCountInString(name) > s.MaxUsernameLength {
-		return errors.New("Too long name")
+		return "", errors.New("Too long name")
+	}
+
+	if strings.HasPrefix(name, game.BotPrefix) {
+		return "", errors.New(fmt.Sprintf("Names starting with '%s' are reserved for bots", game.BotPrefix))
 	}
 
 	logger.Log.Debugf("User from %s logined with login: %s", c.RemoteAddr(), name)
@@ -91,12 +154,12 @@ func login(ctx context.Context) error {
 	// Read password
 	pass, err := io.ReadString('\n')
 	if err != nil {
-		return logger.Trace(err, "Communication error")
+		return "", logger.Trace(err, "Communication error")
 	}
 
 	pass = strings.Replace(strings.Replace(pass, "\n", "", -1), "\r", "", -1)
 	if pass == "" {
-		return errors.New("Empty password")
+		return "", errors.New("Empty password")
 	}
 
 	// Create user if not exists
@@ -107,34 +170,41 @@ func login(ctx context.Context) error {
 		if err != nil {
 			err = logger.Trace(err, "Database error")
 			logger.Log.Critical(err.Error())
-			return err
+			return "", err
 		}
 		logger.Log.Debug("New user created", *u)
 	}
 
-	// Read and validate session id
-	c.Write([]byte("Please, enter the number of game your want to assign: "))
-	line, err = io.ReadString('\n')
-	if err != nil {
-		return logger.Trace(err, "Communication error")
-	}
+	return name, nil
+}
 
+/**
+ * @brief Associate user with session by id
+ * @param[in] c Connection
+ * @param[in] name User's login
+ * @param[in] line Session id typed by user
+ * @return user New User object or error if it occured
+ */
+func (s *Server) joinSession(c net.Conn, name string, line string) (User, error) {
 	line = strings.Replace(strings.Replace(line, "\n", "", -1), "\r", "", -1)
 	if line == "" {
-		return errors.New("Empty session id")
+		return User{}, errors.New("Empty session id")
 	}
 
 	SessionID, err := strconv.Atoi(line)
 	if err != nil {
-		return logger.Trace(err, "Session ID must be a positive integer")
+		return User{}, logger.Trace(err, "Session ID must be a positive integer")
 	} else if int(SessionID) >= len(s.Sessions) {
-		return errors.New(fmt.Sprintf("Session with ID=%d is not exists (Session ID is too big)", SessionID))
+		return User{}, errors.New(fmt.Sprintf("Session with ID=%d is not exists (Session ID is too big)", SessionID))
 	} else if int(SessionID) < 0 {
-		return errors.New("Session ID must be a positive integer")
+		return User{}, errors.New("Session ID must be a positive integer")
 	}
 
-	if len(s.Sessions[SessionID].Users) >= s.Sessions[SessionID].Game.MaxUsersPerGame {
-		return errors.New("Sorry, this game is already starts")
+	membership.Lock()
+	defer membership.Unlock()
+
+	if s.Sessions[SessionID].Game.Full() {
+		return User{}, errors.New("Sorry, this game is already starts")
 	}
 
 	// Create a new user object
@@ -143,25 +213,110 @@ func login(ctx context.Context) error {
 	// Associate user with a session by session id
 	err = s.Sessions[SessionID].Game.AddUser(name)
 	if err != nil {
-		return logger.Trace(err, "Can't accept the game")
+		return User{}, logger.Trace(err, "Can't accept the game")
 	}
 
 	s.Sessions[SessionID].Users = append(s.Sessions[newUser.sessionId].Users, newUser)
 	s.Users[newUser.login] = SessionID
 
-	if len(s.Sessions[SessionID].Users) == s.Sessions[SessionID].Game.MaxUsersPerGame {
-		s.Sessions[SessionID].Game.StartGame()
-		logger.Log.Info("Game started:", s.Sessions[SessionID].Game)
-		errors := make(chan net.Conn)
-		s.broadcast("Game started!", s.SystemLogin, BC_ALL, errors)
-		for c := range errors {
-			logger.Log.Warning("Error occured while sending 'Game started' to", c.RemoteAddr())
-			c.Close()
-		}
-	}
+	return newUser, nil
+}
 
-	user <- newUser
-	logger.Log.Debugf("User from %s associated with session: %d", c.RemoteAddr(), SessionID)
+/**
+ * @brief Current session of user
+ * @param[in] login User's login
+ * @return id Id of session
+ * @return ok Flag if user is in session (false if user is in lobby)
+ */
+func (s *Server) sessionOf(login string) (int, bool) {
+	membership.Lock()
+	defer membership.Unlock()
+
+	id, ok := s.Users[login]
+	return id, ok
+}
 
+/**
+ * @brief Start game if session is full
+ * @param[in] id Id of session
+ * @param[in] errors Channel with failed connections
+ * @return err Error if game can't be started
+ */
+func (s *Server) startIfFull(id int, errors chan<- net.Conn) error {
+	membership.Lock()
+	defer membership.Unlock()
+
+	session := &s.Sessions[id]
+	if !session.Game.Full() || session.Game.Started() {
+		return nil
+	}
+
+	if err := session.Game.StartGame(); err != nil {
+		return logger.Trace(err, "Can't start the game")
+	}
+	logger.Log.Info("Game started:", session.Game)
+	s.notify(id, fmt.Sprintf("Game started! %s", session.Game.Announce()), errors)
 	return nil
 }
+
+/**
+ * @brief Greet user in session and start game if session is full
+ * @param[in] user User, who joined session
+ * @param[in] errors Channel with failed connections
+ *
+ * If game can't be started, users of the session return to lobby
+ */
+func (s *Server) welcome(user User, errors chan<- net.Conn) {
+	s.broadcast(fmt.Sprintf("Welcome %s!\n\rPlease, wait other players...", user.login), user.login, BC_ALL, errors)
+
+	if err := s.startIfFull(user.sessionId, errors); err != nil {
+		logger.Log.Warning(err.Error())
+		s.toLobby(user.sessionId, err.Error(), errors)
+	}
+}
+
+/**
+ * @brief Return all users of session to lobby and clear session
+ * @param[in] id Id of session
+ * @param[in] reason Message for users, why they return to lobby
+ * @param[in] errors Channel with failed connections
+ *
+ * Free session is given to waiting tournament game
+ */
+func (s *Server) toLobby(id int, reason string, errors chan<- net.Conn) {
+	defer s.scheduleTournaments()
+	membership.Lock()
+	defer membership.Unlock()
+	s.clearSession(id, reason, errors)
+}
+
+/**
+ * @brief Return all users of session to lobby and clear session, lock must be taken
+ * @param[in] id Id of session
+ * @param[in] reason Message for users, why they return to lobby
+ * @param[in] errors Channel with failed connections
+ */
+func (s *Server) clearSession(id int, reason string, errors chan<- net.Conn) {
+	s.notify(id, fmt.Sprintf("%s Returned to lobby.\n\r%s", reason, joinPrompt), errors)
+	for _, u := range s.Sessions[id].Users {
+		delete(s.Users, u.login)
+	}
+	s.Sessions[id].Users = nil
+
+	if err := s.Sessions[id].Game.Reset(); err != nil {
+		logger.Log.Critical(logger.Trace(err, "Can't clear session").Error())
+	}
+}
+
+/**
+ * @brief Offer rematch after game is over, players of tournament game return to lobby
+ * @param[in] id Id of session
+ * @param[in] errors Channel with failed connections
+ */
+func (s *Server) afterGame(id int, errors chan<- net.Conn) {
+	if s.Sessions[id].Game.Reserved() {
+		s.toLobby(id, "Tournament game is over.", errors)
+		return
+	}
+	s.notify(id, "Type 'rematch' to play again or 'leave' to return to lobby", errors)
+}